@@ -0,0 +1,122 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	APIKeyStatusActive   = "active"
+	APIKeyStatusInactive = "inactive"
+	APIKeyStatusArchived = "archived"
+)
+
+// APIKey is an organization API key as reported by the Admin API. The key
+// itself is never returned, only a redacted hint such as "sk-ant-api03-R2D...igAA".
+type APIKey struct {
+	ID             string        `json:"id"`
+	Type           string        `json:"type"`
+	Name           string        `json:"name"`
+	Status         string        `json:"status"`
+	PartialKeyHint string        `json:"partial_key_hint"`
+	WorkspaceID    *string       `json:"workspace_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+	CreatedBy      APIKeyCreator `json:"created_by"`
+}
+
+type APIKeyCreator struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type ListAPIKeysParams struct {
+	ListParams
+	Status          string
+	WorkspaceID     string
+	CreatedByUserID string
+}
+
+func (p ListAPIKeysParams) values() url.Values {
+	v := p.ListParams.values()
+	if p.Status != "" {
+		v.Set("status", p.Status)
+	}
+	if p.WorkspaceID != "" {
+		v.Set("workspace_id", p.WorkspaceID)
+	}
+	if p.CreatedByUserID != "" {
+		v.Set("created_by_user_id", p.CreatedByUserID)
+	}
+	return v
+}
+
+// APIKeyUpdateParams holds the fields to change; empty fields are left as
+// they are.
+type APIKeyUpdateParams struct {
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// ListAPIKeys lists the organization's API keys. It requires an admin key; see
+// WithAdminKey.
+func (c *Client) ListAPIKeys(ctx context.Context, params ListAPIKeysParams, opts ...RequestOption) (*Page[APIKey], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/api_keys", params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[APIKey]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListAPIKeysAutoPaging(ctx context.Context, params ListAPIKeysParams, opts ...RequestOption) *PageIterator[APIKey] {
+	return newListIterator(ctx, params.ListParams, func(ctx context.Context, list ListParams) (*Page[APIKey], error) {
+		p := params
+		p.ListParams = list
+		return c.ListAPIKeys(ctx, p, opts...)
+	})
+}
+
+func (c *Client) GetAPIKey(ctx context.Context, id string, opts ...RequestOption) (*APIKey, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/api_keys/"+url.PathEscape(id), nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKey
+	_, err = c.do(req, &key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// UpdateAPIKey renames a key or changes its status. Archiving a key is
+// permanent.
+func (c *Client) UpdateAPIKey(ctx context.Context, id string, params APIKeyUpdateParams, opts ...RequestOption) (*APIKey, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/organizations/api_keys/"+url.PathEscape(id), nil, params, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKey
+	_, err = c.do(req, &key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// adminOptions marks opts as an Admin API request.
+func adminOptions(opts []RequestOption) []RequestOption {
+	return prependOptions(withAdminKey(), opts)
+}
@@ -0,0 +1,126 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAPIKeyJSON = `{"id":"apikey_01Rj2N8SVvo6BePZj99NhmiT","type":"api_key","name":"Developer Key","status":"active","partial_key_hint":"sk-ant-api03-R2D...igAA","workspace_id":"wrkspc_01JwQvzr7rXLA5AGx3HKfFUJ","created_at":"2024-10-30T23:58:27.427722Z","created_by":{"id":"user_01WCz1FkmYMm4gnmykNKUu3Q","type":"user"}}`
+
+func apiKeyJSON(id string) string {
+	return strings.Replace(testAPIKeyJSON, "apikey_01Rj2N8SVvo6BePZj99NhmiT", id, 1)
+}
+
+func TestListAPIKeys(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/organizations/api_keys", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-API-Key"))
+		query := r.URL.Query()
+		assert.Equal(t, APIKeyStatusActive, query.Get("status"))
+		assert.Equal(t, "wrkspc_01", query.Get("workspace_id"))
+		assert.Equal(t, "user_01", query.Get("created_by_user_id"))
+		assert.Equal(t, "20", query.Get("limit"))
+		io.WriteString(w, pageJSON(t, []string{apiKeyJSON("apikey_01")}, false, "apikey_01", "apikey_01"))
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.ListAPIKeys(context.Background(), ListAPIKeysParams{
+		ListParams:      ListParams{Limit: 20},
+		Status:          APIKeyStatusActive,
+		WorkspaceID:     "wrkspc_01",
+		CreatedByUserID: "user_01",
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, page.Data, 1) {
+		return
+	}
+
+	key := page.Data[0]
+	assert.Equal(t, "apikey_01", key.ID)
+	assert.Equal(t, "api_key", key.Type)
+	assert.Equal(t, "Developer Key", key.Name)
+	assert.Equal(t, "sk-ant-api03-R2D...igAA", key.PartialKeyHint)
+	assert.Equal(t, "wrkspc_01JwQvzr7rXLA5AGx3HKfFUJ", *key.WorkspaceID)
+	assert.Equal(t, time.Date(2024, 10, 30, 23, 58, 27, 427722000, time.UTC), key.CreatedAt)
+	assert.Equal(t, APIKeyCreator{ID: "user_01WCz1FkmYMm4gnmykNKUu3Q", Type: "user"}, key.CreatedBy)
+}
+
+func TestListAPIKeysAutoPaging(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, APIKeyStatusInactive, r.URL.Query().Get("status"))
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			io.WriteString(w, pageJSON(t, []string{apiKeyJSON("apikey_a"), apiKeyJSON("apikey_b")}, true, "apikey_a", "apikey_b"))
+		case "apikey_b":
+			io.WriteString(w, pageJSON(t, []string{apiKeyJSON("apikey_c")}, false, "apikey_c", "apikey_c"))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("after_id"))
+		}
+	}, WithAdminKey("admin-key"))
+
+	var ids []string
+	it := client.ListAPIKeysAutoPaging(context.Background(), ListAPIKeysParams{Status: APIKeyStatusInactive})
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"apikey_a", "apikey_b", "apikey_c"}, ids)
+}
+
+func TestGetAPIKey(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/api_keys/apikey_01Rj2N8SVvo6BePZj99NhmiT", r.URL.Path)
+		io.WriteString(w, testAPIKeyJSON)
+	}, WithAdminKey("admin-key"))
+
+	key, err := client.GetAPIKey(context.Background(), "apikey_01Rj2N8SVvo6BePZj99NhmiT")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Developer Key", key.Name)
+		assert.Equal(t, APIKeyStatusActive, key.Status)
+	}
+}
+
+func TestUpdateAPIKey(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/api_keys/apikey_01", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name":"CI Key","status":"inactive"}`, string(body))
+
+		var params APIKeyUpdateParams
+		json.Unmarshal(body, &params)
+		updated := strings.Replace(testAPIKeyJSON, `"Developer Key"`, fmt.Sprintf("%q", params.Name), 1)
+		updated = strings.Replace(updated, `"status":"active"`, fmt.Sprintf(`"status":%q`, params.Status), 1)
+		io.WriteString(w, updated)
+	}, WithAdminKey("admin-key"))
+
+	key, err := client.UpdateAPIKey(context.Background(), "apikey_01", APIKeyUpdateParams{Name: "CI Key", Status: APIKeyStatusInactive})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "CI Key", key.Name)
+		assert.Equal(t, APIKeyStatusInactive, key.Status)
+	}
+}
+
+func TestAPIKeysPermissionError(t *testing.T) {
+	var gotKey string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-API-Key")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"type":"error","error":{"type":"permission_error","message":"This endpoint requires an admin API key"}}`)
+	})
+
+	_, err := client.GetAPIKey(context.Background(), "apikey_01")
+	var permErr *PermissionError
+	if assert.ErrorAs(t, err, &permErr) {
+		assert.Equal(t, "permission_error", permErr.Type)
+	}
+	// without an admin key the regular key is used
+	assert.Equal(t, "test", gotKey)
+}
@@ -0,0 +1,230 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Bucket widths for usage reports. Cost reports only support daily buckets.
+const (
+	BucketWidthMinute = "1m"
+	BucketWidthHour   = "1h"
+	BucketWidthDay    = "1d"
+)
+
+// ReportPage is a page of a usage or cost report. Reports page with an opaque
+// token rather than by ID.
+type ReportPage[T any] struct {
+	Data     []T    `json:"data"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+type UsageReportParams struct {
+	StartingAt time.Time
+	// EndingAt is optional; the report runs to the present when it is zero.
+	EndingAt    time.Time
+	BucketWidth string
+	// GroupBy splits each bucket's results by dimensions such as
+	// "workspace_id", "api_key_id", "model" and "service_tier".
+	GroupBy      []string
+	APIKeyIDs    []string
+	WorkspaceIDs []string
+	Models       []string
+	Limit        int
+	Page         string
+}
+
+func (p UsageReportParams) values() url.Values {
+	v := reportValues(p.StartingAt, p.EndingAt, p.GroupBy, p.Limit, p.Page)
+	if p.BucketWidth != "" {
+		v.Set("bucket_width", p.BucketWidth)
+	}
+	for _, id := range p.APIKeyIDs {
+		v.Add("api_key_ids[]", id)
+	}
+	for _, id := range p.WorkspaceIDs {
+		v.Add("workspace_ids[]", id)
+	}
+	for _, model := range p.Models {
+		v.Add("models[]", model)
+	}
+	return v
+}
+
+type CostReportParams struct {
+	StartingAt time.Time
+	// EndingAt is optional; the report runs to the present when it is zero.
+	EndingAt time.Time
+	// GroupBy splits each bucket's results by "workspace_id" and/or
+	// "description".
+	GroupBy []string
+	Limit   int
+	Page    string
+}
+
+func (p CostReportParams) values() url.Values {
+	return reportValues(p.StartingAt, p.EndingAt, p.GroupBy, p.Limit, p.Page)
+}
+
+func reportValues(startingAt, endingAt time.Time, groupBy []string, limit int, page string) url.Values {
+	v := url.Values{}
+	v.Set("starting_at", startingAt.UTC().Format(time.RFC3339))
+	if !endingAt.IsZero() {
+		v.Set("ending_at", endingAt.UTC().Format(time.RFC3339))
+	}
+	for _, group := range groupBy {
+		v.Add("group_by[]", group)
+	}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	if page != "" {
+		v.Set("page", page)
+	}
+	return v
+}
+
+// UsageBucket holds the token usage within one time bucket, one result per
+// combination of the grouped dimensions.
+type UsageBucket struct {
+	StartingAt time.Time     `json:"starting_at"`
+	EndingAt   time.Time     `json:"ending_at"`
+	Results    []UsageResult `json:"results"`
+}
+
+type UsageResult struct {
+	UncachedInputTokens  int64              `json:"uncached_input_tokens"`
+	CacheCreation        CacheCreationUsage `json:"cache_creation"`
+	CacheReadInputTokens int64              `json:"cache_read_input_tokens"`
+	OutputTokens         int64              `json:"output_tokens"`
+	ServerToolUse        ServerToolUsage    `json:"server_tool_use"`
+
+	// Dimensions, set only when grouped by.
+	APIKeyID    string `json:"api_key_id"`
+	WorkspaceID string `json:"workspace_id"`
+	Model       string `json:"model"`
+	ServiceTier string `json:"service_tier"`
+}
+
+type CacheCreationUsage struct {
+	Ephemeral1hInputTokens int64 `json:"ephemeral_1h_input_tokens"`
+	Ephemeral5mInputTokens int64 `json:"ephemeral_5m_input_tokens"`
+}
+
+type ServerToolUsage struct {
+	WebSearchRequests int64 `json:"web_search_requests"`
+}
+
+// CostBucket holds the cost within one day, one result per combination of the
+// grouped dimensions.
+type CostBucket struct {
+	StartingAt time.Time    `json:"starting_at"`
+	EndingAt   time.Time    `json:"ending_at"`
+	Results    []CostResult `json:"results"`
+}
+
+type CostResult struct {
+	Currency string `json:"currency"`
+	// Amount is in the lowest units of Currency, e.g. cents for USD.
+	Amount float64 `json:"amount,string"`
+
+	// Dimensions, set only when grouped by.
+	WorkspaceID string `json:"workspace_id"`
+	Description string `json:"description"`
+	CostType    string `json:"cost_type"`
+	Model       string `json:"model"`
+	TokenType   string `json:"token_type"`
+	ServiceTier string `json:"service_tier"`
+}
+
+// GetUsageReport returns a page of message token usage buckets.
+func (c *Client) GetUsageReport(ctx context.Context, params UsageReportParams, opts ...RequestOption) (*ReportPage[UsageBucket], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/usage_report/messages", params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page ReportPage[UsageBucket]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) GetUsageReportAutoPaging(ctx context.Context, params UsageReportParams, opts ...RequestOption) *PageIterator[UsageBucket] {
+	return newPageIterator(ctx, func(ctx context.Context, cursor string) ([]UsageBucket, string, bool, error) {
+		p := params
+		if cursor != "" {
+			p.Page = cursor
+		}
+		page, err := c.GetUsageReport(ctx, p, opts...)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return page.Data, page.NextPage, page.HasMore, nil
+	})
+}
+
+// GetCostReport returns a page of daily cost buckets.
+func (c *Client) GetCostReport(ctx context.Context, params CostReportParams, opts ...RequestOption) (*ReportPage[CostBucket], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/cost_report", params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page ReportPage[CostBucket]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) GetCostReportAutoPaging(ctx context.Context, params CostReportParams, opts ...RequestOption) *PageIterator[CostBucket] {
+	return newPageIterator(ctx, func(ctx context.Context, cursor string) ([]CostBucket, string, bool, error) {
+		p := params
+		if cursor != "" {
+			p.Page = cursor
+		}
+		page, err := c.GetCostReport(ctx, p, opts...)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return page.Data, page.NextPage, page.HasMore, nil
+	})
+}
+
+// SumUsage totals the usage across buckets, such as those collected from every
+// page of a report. The dimensions of the total are left empty.
+func SumUsage(buckets []UsageBucket) UsageResult {
+	var total UsageResult
+	for _, bucket := range buckets {
+		for _, r := range bucket.Results {
+			total.UncachedInputTokens += r.UncachedInputTokens
+			total.CacheCreation.Ephemeral1hInputTokens += r.CacheCreation.Ephemeral1hInputTokens
+			total.CacheCreation.Ephemeral5mInputTokens += r.CacheCreation.Ephemeral5mInputTokens
+			total.CacheReadInputTokens += r.CacheReadInputTokens
+			total.OutputTokens += r.OutputTokens
+			total.ServerToolUse.WebSearchRequests += r.ServerToolUse.WebSearchRequests
+		}
+	}
+	return total
+}
+
+// SumCost totals the cost across buckets per currency.
+func SumCost(buckets []CostBucket) map[string]float64 {
+	totals := map[string]float64{}
+	for _, bucket := range buckets {
+		for _, r := range bucket.Results {
+			totals[r.Currency] += r.Amount
+		}
+	}
+	return totals
+}
@@ -0,0 +1,154 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUsageReport(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/usage_report.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/usage_report/messages", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-API-Key"))
+		query := r.URL.Query()
+		assert.Equal(t, "2025-08-01T00:00:00Z", query.Get("starting_at"))
+		assert.Equal(t, "2025-08-08T00:00:00Z", query.Get("ending_at"))
+		assert.Equal(t, BucketWidthDay, query.Get("bucket_width"))
+		assert.Equal(t, []string{"workspace_id", "api_key_id"}, query["group_by[]"])
+		assert.Equal(t, []string{ModelClaude35Sonnet, ModelClaude3Haiku}, query["models[]"])
+		assert.Equal(t, "7", query.Get("limit"))
+		assert.False(t, query.Has("page"))
+		w.Write(fixture)
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.GetUsageReport(context.Background(), UsageReportParams{
+		StartingAt:  time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		EndingAt:    time.Date(2025, 8, 8, 0, 0, 0, 0, time.UTC),
+		BucketWidth: BucketWidthDay,
+		GroupBy:     []string{"workspace_id", "api_key_id"},
+		Models:      []string{ModelClaude35Sonnet, ModelClaude3Haiku},
+		Limit:       7,
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, page.Data, 1) {
+		return
+	}
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "page_MjAyNS0wOC0wMlQwMDowMDowMFo=", page.NextPage)
+
+	bucket := page.Data[0]
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), bucket.StartingAt)
+	assert.Equal(t, time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC), bucket.EndingAt)
+	if assert.Len(t, bucket.Results, 2) {
+		assert.Equal(t, UsageResult{
+			UncachedInputTokens:  1500,
+			CacheCreation:        CacheCreationUsage{Ephemeral1hInputTokens: 1000, Ephemeral5mInputTokens: 500},
+			CacheReadInputTokens: 200,
+			OutputTokens:         500,
+			ServerToolUse:        ServerToolUsage{WebSearchRequests: 10},
+			APIKeyID:             "apikey_01Rj2N8SVvo6BePZj99NhmiT",
+			WorkspaceID:          "wrkspc_01JwQvzr7rXLA5AGx3HKfFUJ",
+			Model:                ModelClaude35Sonnet,
+			ServiceTier:          "standard",
+		}, bucket.Results[0])
+	}
+}
+
+func TestGetCostReport(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/cost_report.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/cost_report", r.URL.Path)
+		assert.Equal(t, []string{"workspace_id", "description"}, r.URL.Query()["group_by[]"])
+		assert.False(t, r.URL.Query().Has("ending_at"))
+		w.Write(fixture)
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.GetCostReport(context.Background(), CostReportParams{
+		StartingAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		GroupBy:    []string{"workspace_id", "description"},
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, page.Data, 1) {
+		return
+	}
+	assert.False(t, page.HasMore)
+
+	results := page.Data[0].Results
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "USD", results[0].Currency)
+		assert.InDelta(t, 123.78912, results[0].Amount, 1e-9)
+		assert.Equal(t, "tokens", results[0].CostType)
+		assert.Equal(t, "uncached_input_tokens", results[0].TokenType)
+		assert.Equal(t, "Web Search Usage", results[1].Description)
+		assert.Empty(t, results[1].Model)
+	}
+	assert.InDelta(t, 144.28912, SumCost(page.Data)["USD"], 1e-9)
+}
+
+func TestGetUsageReportAutoPaging(t *testing.T) {
+	bucket := func(day, input, output int) string {
+		return fmt.Sprintf(`{"starting_at":"2025-08-%02dT00:00:00Z","ending_at":"2025-08-%02dT00:00:00Z","results":[{"uncached_input_tokens":%d,"output_tokens":%d,"cache_creation":{"ephemeral_5m_input_tokens":1},"server_tool_use":{"web_search_requests":1}}]}`, day, day+1, input, output)
+	}
+
+	var pages []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		switch page {
+		case "":
+			fmt.Fprintf(w, `{"data":[%s,%s],"has_more":true,"next_page":"page_2"}`, bucket(1, 100, 10), bucket(2, 200, 20))
+		case "page_2":
+			fmt.Fprintf(w, `{"data":[%s],"has_more":false,"next_page":null}`, bucket(3, 300, 30))
+		default:
+			t.Errorf("unexpected page %q", page)
+		}
+	}, WithAdminKey("admin-key"))
+
+	var buckets []UsageBucket
+	it := client.GetUsageReportAutoPaging(context.Background(), UsageReportParams{
+		StartingAt:  time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		BucketWidth: BucketWidthDay,
+	})
+	for it.Next() {
+		buckets = append(buckets, it.Current())
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"", "page_2"}, pages)
+	if assert.Len(t, buckets, 3) {
+		assert.Equal(t, 3, buckets[2].StartingAt.Day())
+	}
+
+	total := SumUsage(buckets)
+	assert.Equal(t, int64(600), total.UncachedInputTokens)
+	assert.Equal(t, int64(60), total.OutputTokens)
+	assert.Equal(t, int64(3), total.CacheCreation.Ephemeral5mInputTokens)
+	assert.Equal(t, int64(3), total.ServerToolUse.WebSearchRequests)
+}
+
+func TestGetCostReportAutoPaging(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"starting_at":"2025-08-01T00:00:00Z","ending_at":"2025-08-02T00:00:00Z","results":[{"currency":"USD","amount":"1.5"}]}],"has_more":true,"next_page":"page_2"}`)
+		case "page_2":
+			fmt.Fprint(w, `{"data":[{"starting_at":"2025-08-02T00:00:00Z","ending_at":"2025-08-03T00:00:00Z","results":[{"currency":"USD","amount":"2.25"}]}],"has_more":false,"next_page":null}`)
+		}
+	}, WithAdminKey("admin-key"))
+
+	var buckets []CostBucket
+	it := client.GetCostReportAutoPaging(context.Background(), CostReportParams{StartingAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)})
+	for it.Next() {
+		buckets = append(buckets, it.Current())
+	}
+	assert.NoError(t, it.Err())
+	assert.Len(t, buckets, 2)
+	assert.Equal(t, map[string]float64{"USD": 3.75}, SumCost(buckets))
+}
@@ -0,0 +1,198 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	OrganizationRoleUser      = "user"
+	OrganizationRoleDeveloper = "developer"
+	OrganizationRoleBilling   = "billing"
+	OrganizationRoleAdmin     = "admin"
+
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusExpired  = "expired"
+	InviteStatusDeleted  = "deleted"
+)
+
+// User is a member of the organization.
+type User struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Email   string    `json:"email"`
+	Name    string    `json:"name"`
+	Role    string    `json:"role"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type DeletedUser struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Invite is an invitation for someone to join the organization.
+type Invite struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Status    string    `json:"status"`
+	InvitedAt time.Time `json:"invited_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type DeletedInvite struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type ListUsersParams struct {
+	ListParams
+	Email string
+}
+
+func (p ListUsersParams) values() url.Values {
+	v := p.ListParams.values()
+	if p.Email != "" {
+		v.Set("email", p.Email)
+	}
+	return v
+}
+
+type userParams struct {
+	Role string `json:"role"`
+}
+
+type inviteParams struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (c *Client) ListUsers(ctx context.Context, params ListUsersParams, opts ...RequestOption) (*Page[User], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/users", params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[User]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListUsersAutoPaging(ctx context.Context, params ListUsersParams, opts ...RequestOption) *PageIterator[User] {
+	return newListIterator(ctx, params.ListParams, func(ctx context.Context, list ListParams) (*Page[User], error) {
+		p := params
+		p.ListParams = list
+		return c.ListUsers(ctx, p, opts...)
+	})
+}
+
+func (c *Client) GetUser(ctx context.Context, id string, opts ...RequestOption) (*User, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/users/"+url.PathEscape(id), nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	_, err = c.do(req, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UpdateUser changes a user's organization role, one of the OrganizationRole
+// constants.
+func (c *Client) UpdateUser(ctx context.Context, id, role string, opts ...RequestOption) (*User, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/organizations/users/"+url.PathEscape(id), nil, userParams{Role: role}, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	_, err = c.do(req, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// RemoveUser removes a user from the organization, revoking their access.
+func (c *Client) RemoveUser(ctx context.Context, id string, opts ...RequestOption) (*DeletedUser, error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/v1/organizations/users/"+url.PathEscape(id), nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted DeletedUser
+	_, err = c.do(req, &deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
+
+// CreateInvite invites email to join the organization with role, one of the
+// OrganizationRole constants.
+func (c *Client) CreateInvite(ctx context.Context, email, role string, opts ...RequestOption) (*Invite, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/organizations/invites", nil, inviteParams{Email: email, Role: role}, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var invite Invite
+	_, err = c.do(req, &invite)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (c *Client) ListInvites(ctx context.Context, params ListParams, opts ...RequestOption) (*Page[Invite], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/invites", params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[Invite]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListInvitesAutoPaging(ctx context.Context, params ListParams, opts ...RequestOption) *PageIterator[Invite] {
+	return newListIterator(ctx, params, func(ctx context.Context, params ListParams) (*Page[Invite], error) {
+		return c.ListInvites(ctx, params, opts...)
+	})
+}
+
+// DeleteInvite withdraws a pending invite.
+func (c *Client) DeleteInvite(ctx context.Context, id string, opts ...RequestOption) (*DeletedInvite, error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/v1/organizations/invites/"+url.PathEscape(id), nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted DeletedInvite
+	_, err = c.do(req, &deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
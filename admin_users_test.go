@@ -0,0 +1,158 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testUserJSON   = `{"id":"user_01WCz1FkmYMm4gnmykNKUu3Q","type":"user","email":"user@example.com","name":"Jane Doe","role":"developer","added_at":"2024-10-30T23:58:27.427722Z"}`
+	testInviteJSON = `{"id":"invite_015gWxCN9Hfg2QhZwTK7Mdeu","type":"invite","email":"new@example.com","role":"user","status":"pending","invited_at":"2024-10-30T23:58:27.427722Z","expires_at":"2024-11-20T23:58:27.427722Z"}`
+)
+
+func TestListUsers(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/organizations/users", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "user@example.com", r.URL.Query().Get("email"))
+		fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"user_01","last_id":"user_01"}`, testUserJSON)
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.ListUsers(context.Background(), ListUsersParams{Email: "user@example.com"})
+	if assert.NoError(t, err) && assert.Len(t, page.Data, 1) {
+		user := page.Data[0]
+		assert.Equal(t, "user_01WCz1FkmYMm4gnmykNKUu3Q", user.ID)
+		assert.Equal(t, "Jane Doe", user.Name)
+		assert.Equal(t, OrganizationRoleDeveloper, user.Role)
+		assert.Equal(t, time.Date(2024, 10, 30, 23, 58, 27, 427722000, time.UTC), user.AddedAt)
+	}
+}
+
+func TestListUsersAutoPaging(t *testing.T) {
+	user := func(id string) string {
+		return fmt.Sprintf(`{"id":%q,"type":"user","email":"%s@example.com","role":"user"}`, id, id)
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			fmt.Fprintf(w, `{"data":[%s,%s],"has_more":true,"first_id":"user_a","last_id":"user_b"}`, user("user_a"), user("user_b"))
+		case "user_b":
+			fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"user_c","last_id":"user_c"}`, user("user_c"))
+		}
+	}, WithAdminKey("admin-key"))
+
+	var ids []string
+	it := client.ListUsersAutoPaging(context.Background(), ListUsersParams{})
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"user_a", "user_b", "user_c"}, ids)
+}
+
+func TestGetUser(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/users/user_01WCz1FkmYMm4gnmykNKUu3Q", r.URL.Path)
+		io.WriteString(w, testUserJSON)
+	}, WithAdminKey("admin-key"))
+
+	user, err := client.GetUser(context.Background(), "user_01WCz1FkmYMm4gnmykNKUu3Q")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user@example.com", user.Email)
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/users/user_01", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"role":"admin"}`, string(body))
+		io.WriteString(w, `{"id":"user_01","type":"user","email":"user@example.com","role":"admin"}`)
+	}, WithAdminKey("admin-key"))
+
+	user, err := client.UpdateUser(context.Background(), "user_01", OrganizationRoleAdmin)
+	if assert.NoError(t, err) {
+		assert.Equal(t, OrganizationRoleAdmin, user.Role)
+	}
+}
+
+func TestRemoveUser(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/v1/organizations/users/user_01", r.URL.Path)
+		io.WriteString(w, `{"id":"user_01","type":"user_deleted"}`)
+	}, WithAdminKey("admin-key"))
+
+	deleted, err := client.RemoveUser(context.Background(), "user_01")
+	if assert.NoError(t, err) {
+		assert.Equal(t, DeletedUser{ID: "user_01", Type: "user_deleted"}, *deleted)
+	}
+}
+
+func TestCreateInvite(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/invites", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"email":"new@example.com","role":"user"}`, string(body))
+		io.WriteString(w, testInviteJSON)
+	}, WithAdminKey("admin-key"))
+
+	invite, err := client.CreateInvite(context.Background(), "new@example.com", OrganizationRoleUser)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "invite_015gWxCN9Hfg2QhZwTK7Mdeu", invite.ID)
+	assert.Equal(t, InviteStatusPending, invite.Status)
+	assert.Equal(t, time.Date(2024, 10, 30, 23, 58, 27, 427722000, time.UTC), invite.InvitedAt)
+	assert.Equal(t, 21*24*time.Hour, invite.ExpiresAt.Sub(invite.InvitedAt))
+}
+
+func TestListInvites(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/invites", r.URL.Path)
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"invite_01","last_id":"invite_01"}`, testInviteJSON)
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.ListInvites(context.Background(), ListParams{Limit: 5})
+	if assert.NoError(t, err) && assert.Len(t, page.Data, 1) {
+		assert.Equal(t, "new@example.com", page.Data[0].Email)
+	}
+}
+
+func TestDeleteInvite(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/v1/organizations/invites/invite_01", r.URL.Path)
+		io.WriteString(w, `{"id":"invite_01","type":"invite_deleted"}`)
+	}, WithAdminKey("admin-key"))
+
+	deleted, err := client.DeleteInvite(context.Background(), "invite_01")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "invite_deleted", deleted.Type)
+	}
+}
+
+func TestAdminUsersPermissionError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"type":"error","error":{"type":"permission_error","message":"Admin key required"}}`)
+	})
+
+	var permErr *PermissionError
+	_, err := client.RemoveUser(context.Background(), "user_01")
+	assert.ErrorAs(t, err, &permErr)
+	_, err = client.CreateInvite(context.Background(), "new@example.com", OrganizationRoleUser)
+	assert.ErrorAs(t, err, &permErr)
+	_, err = client.ListInvites(context.Background(), ListParams{})
+	assert.ErrorAs(t, err, &permErr)
+}
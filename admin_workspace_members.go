@@ -0,0 +1,134 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	WorkspaceRoleUser      = "workspace_user"
+	WorkspaceRoleDeveloper = "workspace_developer"
+	WorkspaceRoleAdmin     = "workspace_admin"
+	WorkspaceRoleBilling   = "workspace_billing"
+)
+
+// InvalidWorkspaceRoleError is returned, before any request is made, for a
+// role other than the WorkspaceRole constants.
+type InvalidWorkspaceRoleError struct {
+	Role string
+}
+
+func (e *InvalidWorkspaceRoleError) Error() string {
+	return fmt.Sprintf("anthropic: invalid workspace role %q", e.Role)
+}
+
+func validateWorkspaceRole(role string) error {
+	switch role {
+	case WorkspaceRoleUser, WorkspaceRoleDeveloper, WorkspaceRoleAdmin, WorkspaceRoleBilling:
+		return nil
+	}
+	return &InvalidWorkspaceRoleError{Role: role}
+}
+
+type WorkspaceMember struct {
+	Type          string `json:"type"`
+	UserID        string `json:"user_id"`
+	WorkspaceID   string `json:"workspace_id"`
+	WorkspaceRole string `json:"workspace_role"`
+}
+
+type DeletedWorkspaceMember struct {
+	Type        string `json:"type"`
+	UserID      string `json:"user_id"`
+	WorkspaceID string `json:"workspace_id"`
+}
+
+type workspaceMemberParams struct {
+	UserID        string `json:"user_id,omitempty"`
+	WorkspaceRole string `json:"workspace_role"`
+}
+
+func workspaceMembersPath(workspaceID string) string {
+	return "/v1/organizations/workspaces/" + url.PathEscape(workspaceID) + "/members"
+}
+
+func (c *Client) ListWorkspaceMembers(ctx context.Context, workspaceID string, params ListParams, opts ...RequestOption) (*Page[WorkspaceMember], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, workspaceMembersPath(workspaceID), params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[WorkspaceMember]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListWorkspaceMembersAutoPaging(ctx context.Context, workspaceID string, params ListParams, opts ...RequestOption) *PageIterator[WorkspaceMember] {
+	return newListIterator(ctx, params, func(ctx context.Context, params ListParams) (*Page[WorkspaceMember], error) {
+		return c.ListWorkspaceMembers(ctx, workspaceID, params, opts...)
+	})
+}
+
+// AddWorkspaceMember adds an organization user to a workspace with role, one
+// of the WorkspaceRole constants.
+func (c *Client) AddWorkspaceMember(ctx context.Context, workspaceID, userID, role string, opts ...RequestOption) (*WorkspaceMember, error) {
+	if err := validateWorkspaceRole(role); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, workspaceMembersPath(workspaceID), nil,
+		workspaceMemberParams{UserID: userID, WorkspaceRole: role}, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var member WorkspaceMember
+	_, err = c.do(req, &member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// UpdateWorkspaceMember changes a member's role.
+func (c *Client) UpdateWorkspaceMember(ctx context.Context, workspaceID, userID, role string, opts ...RequestOption) (*WorkspaceMember, error) {
+	if err := validateWorkspaceRole(role); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, workspaceMembersPath(workspaceID)+"/"+url.PathEscape(userID), nil,
+		workspaceMemberParams{WorkspaceRole: role}, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var member WorkspaceMember
+	_, err = c.do(req, &member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (c *Client) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string, opts ...RequestOption) (*DeletedWorkspaceMember, error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, workspaceMembersPath(workspaceID)+"/"+url.PathEscape(userID), nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted DeletedWorkspaceMember
+	_, err = c.do(req, &deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
@@ -0,0 +1,117 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func workspaceMemberJSON(userID, role string) string {
+	return fmt.Sprintf(`{"type":"workspace_member","user_id":%q,"workspace_id":"wrkspc_01","workspace_role":%q}`, userID, role)
+}
+
+func TestListWorkspaceMembers(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01/members", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"user_01","last_id":"user_01"}`, workspaceMemberJSON("user_01", WorkspaceRoleDeveloper))
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.ListWorkspaceMembers(context.Background(), "wrkspc_01", ListParams{Limit: 10})
+	if assert.NoError(t, err) && assert.Len(t, page.Data, 1) {
+		assert.Equal(t, WorkspaceMember{
+			Type:          "workspace_member",
+			UserID:        "user_01",
+			WorkspaceID:   "wrkspc_01",
+			WorkspaceRole: WorkspaceRoleDeveloper,
+		}, page.Data[0])
+	}
+}
+
+func TestListWorkspaceMembersAutoPaging(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01/members", r.URL.Path)
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			fmt.Fprintf(w, `{"data":[%s,%s],"has_more":true,"first_id":"user_a","last_id":"user_b"}`,
+				workspaceMemberJSON("user_a", WorkspaceRoleUser), workspaceMemberJSON("user_b", WorkspaceRoleAdmin))
+		case "user_b":
+			fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"user_c","last_id":"user_c"}`, workspaceMemberJSON("user_c", WorkspaceRoleBilling))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("after_id"))
+		}
+	}, WithAdminKey("admin-key"))
+
+	var ids []string
+	it := client.ListWorkspaceMembersAutoPaging(context.Background(), "wrkspc_01", ListParams{})
+	for it.Next() {
+		ids = append(ids, it.Current().UserID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"user_a", "user_b", "user_c"}, ids)
+}
+
+func TestAddWorkspaceMember(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01/members", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"user_id":"user_01","workspace_role":"workspace_developer"}`, string(body))
+		io.WriteString(w, workspaceMemberJSON("user_01", WorkspaceRoleDeveloper))
+	}, WithAdminKey("admin-key"))
+
+	member, err := client.AddWorkspaceMember(context.Background(), "wrkspc_01", "user_01", WorkspaceRoleDeveloper)
+	if assert.NoError(t, err) {
+		assert.Equal(t, WorkspaceRoleDeveloper, member.WorkspaceRole)
+	}
+}
+
+func TestUpdateWorkspaceMember(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01/members/user_01", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"workspace_role":"workspace_admin"}`, string(body))
+		io.WriteString(w, workspaceMemberJSON("user_01", WorkspaceRoleAdmin))
+	}, WithAdminKey("admin-key"))
+
+	member, err := client.UpdateWorkspaceMember(context.Background(), "wrkspc_01", "user_01", WorkspaceRoleAdmin)
+	if assert.NoError(t, err) {
+		assert.Equal(t, WorkspaceRoleAdmin, member.WorkspaceRole)
+	}
+}
+
+func TestRemoveWorkspaceMember(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01/members/user_01", r.URL.Path)
+		io.WriteString(w, `{"type":"workspace_member_deleted","user_id":"user_01","workspace_id":"wrkspc_01"}`)
+	}, WithAdminKey("admin-key"))
+
+	deleted, err := client.RemoveWorkspaceMember(context.Background(), "wrkspc_01", "user_01")
+	if assert.NoError(t, err) {
+		assert.Equal(t, DeletedWorkspaceMember{Type: "workspace_member_deleted", UserID: "user_01", WorkspaceID: "wrkspc_01"}, *deleted)
+	}
+}
+
+func TestWorkspaceMemberInvalidRole(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("invalid roles must be rejected locally")
+	}, WithAdminKey("admin-key"))
+
+	_, err := client.AddWorkspaceMember(context.Background(), "wrkspc_01", "user_01", "workspace_owner")
+	var roleErr *InvalidWorkspaceRoleError
+	if assert.ErrorAs(t, err, &roleErr) {
+		assert.Equal(t, "workspace_owner", roleErr.Role)
+	}
+
+	_, err = client.UpdateWorkspaceMember(context.Background(), "wrkspc_01", "user_01", strings.ToUpper(WorkspaceRoleAdmin))
+	assert.ErrorAs(t, err, &roleErr)
+}
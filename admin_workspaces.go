@@ -0,0 +1,126 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Workspace groups API keys and usage within an organization.
+type Workspace struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Name         string     `json:"name"`
+	DisplayColor string     `json:"display_color"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ArchivedAt   *time.Time `json:"archived_at"`
+}
+
+type ListWorkspacesParams struct {
+	ListParams
+	IncludeArchived bool
+}
+
+func (p ListWorkspacesParams) values() url.Values {
+	v := p.ListParams.values()
+	if p.IncludeArchived {
+		v.Set("include_archived", strconv.FormatBool(p.IncludeArchived))
+	}
+	return v
+}
+
+type workspaceParams struct {
+	Name string `json:"name"`
+}
+
+// CreateWorkspace creates a workspace. Workspace names are unique within the
+// organization; reusing one returns a *ConflictError.
+func (c *Client) CreateWorkspace(ctx context.Context, name string, opts ...RequestOption) (*Workspace, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/organizations/workspaces", nil, workspaceParams{Name: name}, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace Workspace
+	_, err = c.do(req, &workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (c *Client) GetWorkspace(ctx context.Context, id string, opts ...RequestOption) (*Workspace, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/workspaces/"+url.PathEscape(id), nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace Workspace
+	_, err = c.do(req, &workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+// ListWorkspaces lists the organization's workspaces, leaving out archived
+// ones unless params.IncludeArchived is set.
+func (c *Client) ListWorkspaces(ctx context.Context, params ListWorkspacesParams, opts ...RequestOption) (*Page[Workspace], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/organizations/workspaces", params.values(), nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[Workspace]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListWorkspacesAutoPaging(ctx context.Context, params ListWorkspacesParams, opts ...RequestOption) *PageIterator[Workspace] {
+	return newListIterator(ctx, params.ListParams, func(ctx context.Context, list ListParams) (*Page[Workspace], error) {
+		p := params
+		p.ListParams = list
+		return c.ListWorkspaces(ctx, p, opts...)
+	})
+}
+
+// UpdateWorkspace renames a workspace.
+func (c *Client) UpdateWorkspace(ctx context.Context, id, name string, opts ...RequestOption) (*Workspace, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/organizations/workspaces/"+url.PathEscape(id), nil, workspaceParams{Name: name}, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace Workspace
+	_, err = c.do(req, &workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+// ArchiveWorkspace archives a workspace, deactivating its API keys. Archived
+// workspaces can't be restored.
+func (c *Client) ArchiveWorkspace(ctx context.Context, id string, opts ...RequestOption) (*Workspace, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/organizations/workspaces/"+url.PathEscape(id)+"/archive", nil, nil, adminOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace Workspace
+	_, err = c.do(req, &workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
@@ -0,0 +1,162 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWorkspace(t *testing.T) {
+	golden, err := os.ReadFile("testdata/workspace_create_request.json")
+	assert.NoError(t, err)
+	fixture, err := os.ReadFile("testdata/workspace_response.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces", r.URL.Path)
+		assert.Equal(t, "admin-key", r.Header.Get("X-API-Key"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, string(golden), string(body))
+		w.Write(fixture)
+	}, WithAdminKey("admin-key"))
+
+	workspace, err := client.CreateWorkspace(context.Background(), "customer-acme")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "wrkspc_01JwQvzr7rXLA5AGx3HKfFUJ", workspace.ID)
+	assert.Equal(t, "workspace", workspace.Type)
+	assert.Equal(t, "customer-acme", workspace.Name)
+	assert.Equal(t, "#6C5BB9", workspace.DisplayColor)
+	assert.Equal(t, time.Date(2024, 10, 30, 23, 58, 27, 427722000, time.UTC), workspace.CreatedAt)
+	assert.Nil(t, workspace.ArchivedAt)
+}
+
+func TestCreateWorkspaceDuplicateName(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"A workspace with this name already exists"}}`)
+	}, WithAdminKey("admin-key"))
+
+	_, err := client.CreateWorkspace(context.Background(), "customer-acme")
+	var conflict *ConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Contains(t, conflict.Message, "already exists")
+	}
+}
+
+func TestGetWorkspace(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/workspace_response.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/organizations/workspaces/wrkspc_missing" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"Workspace not found"}}`)
+			return
+		}
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01JwQvzr7rXLA5AGx3HKfFUJ", r.URL.Path)
+		w.Write(fixture)
+	}, WithAdminKey("admin-key"))
+
+	workspace, err := client.GetWorkspace(context.Background(), "wrkspc_01JwQvzr7rXLA5AGx3HKfFUJ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "customer-acme", workspace.Name)
+	}
+
+	_, err = client.GetWorkspace(context.Background(), "wrkspc_missing")
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestListWorkspacesArchived(t *testing.T) {
+	active := `{"id":"wrkspc_active","type":"workspace","name":"active","display_color":"#6C5BB9","created_at":"2024-10-30T23:58:27Z","archived_at":null}`
+	archived := `{"id":"wrkspc_archived","type":"workspace","name":"archived","display_color":"#6C5BB9","created_at":"2024-10-30T23:58:27Z","archived_at":"2024-11-01T00:00:00Z"}`
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/organizations/workspaces", r.URL.Path)
+		if r.URL.Query().Get("include_archived") == "true" {
+			fmt.Fprintf(w, `{"data":[%s,%s],"has_more":false,"first_id":"wrkspc_active","last_id":"wrkspc_archived"}`, active, archived)
+			return
+		}
+		assert.False(t, r.URL.Query().Has("include_archived"))
+		fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"wrkspc_active","last_id":"wrkspc_active"}`, active)
+	}, WithAdminKey("admin-key"))
+
+	page, err := client.ListWorkspaces(context.Background(), ListWorkspacesParams{})
+	if assert.NoError(t, err) && assert.Len(t, page.Data, 1) {
+		assert.Nil(t, page.Data[0].ArchivedAt)
+	}
+
+	page, err = client.ListWorkspaces(context.Background(), ListWorkspacesParams{IncludeArchived: true})
+	if assert.NoError(t, err) && assert.Len(t, page.Data, 2) {
+		if assert.NotNil(t, page.Data[1].ArchivedAt) {
+			assert.Equal(t, time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), *page.Data[1].ArchivedAt)
+		}
+	}
+}
+
+func TestListWorkspacesAutoPaging(t *testing.T) {
+	workspace := func(id string) string {
+		return fmt.Sprintf(`{"id":%q,"type":"workspace","name":%q,"created_at":"2024-10-30T23:58:27Z"}`, id, id)
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("include_archived"))
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			fmt.Fprintf(w, `{"data":[%s,%s],"has_more":true,"first_id":"wrkspc_a","last_id":"wrkspc_b"}`, workspace("wrkspc_a"), workspace("wrkspc_b"))
+		case "wrkspc_b":
+			fmt.Fprintf(w, `{"data":[%s],"has_more":false,"first_id":"wrkspc_c","last_id":"wrkspc_c"}`, workspace("wrkspc_c"))
+		}
+	}, WithAdminKey("admin-key"))
+
+	var ids []string
+	it := client.ListWorkspacesAutoPaging(context.Background(), ListWorkspacesParams{IncludeArchived: true})
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"wrkspc_a", "wrkspc_b", "wrkspc_c"}, ids)
+}
+
+func TestUpdateWorkspace(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/workspace_response.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name":"customer-acme-eu"}`, string(body))
+		io.WriteString(w, strings.Replace(string(fixture), `"customer-acme"`, `"customer-acme-eu"`, 1))
+	}, WithAdminKey("admin-key"))
+
+	workspace, err := client.UpdateWorkspace(context.Background(), "wrkspc_01", "customer-acme-eu")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "customer-acme-eu", workspace.Name)
+	}
+}
+
+func TestArchiveWorkspace(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/workspace_response.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/organizations/workspaces/wrkspc_01/archive", r.URL.Path)
+		io.WriteString(w, strings.Replace(string(fixture), `"archived_at": null`, `"archived_at": "2024-11-01T00:00:00Z"`, 1))
+	}, WithAdminKey("admin-key"))
+
+	workspace, err := client.ArchiveWorkspace(context.Background(), "wrkspc_01")
+	if assert.NoError(t, err) && assert.NotNil(t, workspace.ArchivedAt) {
+		assert.Equal(t, 2024, workspace.ArchivedAt.Year())
+	}
+}
@@ -0,0 +1,127 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// WithAutoStream makes CreateMessage send its requests over the streaming
+// transport and assemble the events into the *Message it returns. Generations
+// that take minutes, which proxies often cut off while waiting for a
+// non-streaming response, then arrive as they are produced. Errors before the
+// stream starts are returned as usual; one that ends it early is returned as a
+// *PartialMessageError.
+func WithAutoStream() ClientOption {
+	return func(c *Client) {
+		c.autoStream = true
+	}
+}
+
+// WithRequestAutoStream is WithAutoStream for a single CreateMessage call.
+func WithRequestAutoStream() RequestOption {
+	return func(r *requestConfig) {
+		r.autoStream = true
+	}
+}
+
+// PartialMessageError is returned by CreateMessage with auto-streaming when
+// the stream fails partway. Message holds what was received before Err.
+type PartialMessageError struct {
+	Message *Message
+	Err     error
+}
+
+func (e *PartialMessageError) Error() string {
+	return fmt.Sprintf("anthropic: message stream interrupted: %v", e.Err)
+}
+
+func (e *PartialMessageError) Unwrap() error {
+	return e.Err
+}
+
+// createMessageStreamed sends params over the streaming transport and
+// assembles the message.
+func (c *Client) createMessageStreamed(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*Message, error) {
+	stream, err := c.StreamMessage(ctx, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return accumulateMessage(stream, nil)
+}
+
+// accumulateMessage reads stream to the end and returns the message its
+// events describe. fn, if not nil, is passed each event first; an error from
+// it stops reading and is returned as is.
+func accumulateMessage(stream *MessageStream, fn func(*MessageStreamEvent) error) (*Message, error) {
+	var msg Message
+	inputs := make(map[int]*ToolInputAccumulator)
+	for stream.Next() {
+		event := stream.Event()
+		if fn != nil {
+			if err := fn(event); err != nil {
+				return nil, err
+			}
+		}
+		switch event.Type {
+		case StreamEventMessageStart:
+			if event.Message != nil {
+				msg = *event.Message
+			}
+		case StreamEventContentBlockStart:
+			if event.ContentBlock == nil {
+				continue
+			}
+			for len(msg.Content) <= event.Index {
+				msg.Content = append(msg.Content, ContentBlock{})
+			}
+			msg.Content[event.Index] = *event.ContentBlock
+		case StreamEventContentBlockDelta:
+			if event.ContentBlock == nil || event.Index >= len(msg.Content) {
+				continue
+			}
+			block := &msg.Content[event.Index]
+			switch event.ContentBlock.Type {
+			case "text_delta":
+				block.Text += event.ContentBlock.Text
+			case "thinking_delta":
+				block.Thinking += event.ContentBlock.Thinking
+			case "signature_delta":
+				block.Signature += event.ContentBlock.Signature
+			case "input_json_delta":
+				if inputs[event.Index] == nil {
+					inputs[event.Index] = &ToolInputAccumulator{}
+				}
+				inputs[event.Index].Feed(event.ContentBlock.PartialJSON)
+			}
+		case StreamEventContentBlockStop:
+			input, ok := inputs[event.Index]
+			if !ok || event.Index >= len(msg.Content) {
+				continue
+			}
+			value, err := input.Value()
+			if err != nil {
+				return nil, &PartialMessageError{Message: &msg, Err: err}
+			}
+			msg.Content[event.Index].Input = value
+		case StreamEventMessageDelta:
+			if event.Delta == nil {
+				continue
+			}
+			msg.StopReason = event.Delta.StopReason
+			if event.Delta.StopSequence != nil {
+				msg.StopSequence = *event.Delta.StopSequence
+			}
+		}
+	}
+	msg.Usage = stream.Usage()
+
+	if err := stream.Err(); err != nil {
+		return nil, &PartialMessageError{Message: &msg, Err: err}
+	}
+	if !stream.Done() {
+		return nil, &PartialMessageError{Message: &msg, Err: io.ErrUnexpectedEOF}
+	}
+	return &msg, nil
+}
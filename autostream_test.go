@@ -0,0 +1,130 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const autoStreamMessage = `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307",` +
+	`"content":[{"type":"text","text":"Let me check the weather."},{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{"city":"Paris","days":3}}],` +
+	`"stop_reason":"tool_use","stop_sequence":null,"usage":{"input_tokens":12,"output_tokens":40}}`
+
+// autoStreamTranscript streams the same message as autoStreamMessage.
+const autoStreamTranscript = "event: message_start\n" +
+	"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"model\":\"claude-3-haiku-20240307\",\"content\":[],\"stop_reason\":null,\"stop_sequence\":null,\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n" +
+	"event: content_block_start\n" +
+	"data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Let me check \"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"the weather.\"}}\n\n" +
+	"event: content_block_stop\n" +
+	"data: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+	"event: content_block_start\n" +
+	"data: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_01\",\"name\":\"get_weather\",\"input\":{}}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\\\"Par\"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"is\\\",\\\"days\\\":3}\"}}\n\n" +
+	"event: content_block_stop\n" +
+	"data: {\"type\":\"content_block_stop\",\"index\":1}\n\n" +
+	"event: message_delta\n" +
+	"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":40}}\n\n" +
+	"event: message_stop\n" +
+	"data: {\"type\":\"message_stop\"}\n\n"
+
+// autoStreamHandler answers message requests with autoStreamMessage, or with
+// transcript if they ask to stream.
+func autoStreamHandler(t *testing.T, transcript string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		if !params.Stream {
+			io.WriteString(w, autoStreamMessage)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, transcript)
+	}
+}
+
+func TestWithAutoStream(t *testing.T) {
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Weather in Paris?"}}}
+
+	direct, err := newTestClient(t, autoStreamHandler(t, autoStreamTranscript)).CreateMessage(context.Background(), params)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	streamed, err := newTestClient(t, autoStreamHandler(t, autoStreamTranscript), WithAutoStream()).CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, direct, streamed)
+
+	var meta ResponseMeta
+	client := newTestClient(t, autoStreamHandler(t, autoStreamTranscript))
+	streamed, err = client.CreateMessage(context.Background(), params, WithRequestAutoStream(), WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, direct, streamed)
+	assert.Equal(t, "text/event-stream", meta.ContentType)
+}
+
+func TestWithAutoStreamInterrupted(t *testing.T) {
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Weather in Paris?"}}}
+
+	// an error event after the first block
+	secondBlock := strings.LastIndex(autoStreamTranscript[:strings.Index(autoStreamTranscript, `"index":1`)], "event:")
+	failed := autoStreamTranscript[:secondBlock] +
+		"event: error\n" +
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+	_, err := newTestClient(t, autoStreamHandler(t, failed), WithAutoStream()).CreateMessage(context.Background(), params)
+	var partial *PartialMessageError
+	if assert.ErrorAs(t, err, &partial) {
+		assert.EqualError(t, partial.Err, "anthropic: overloaded_error: Overloaded")
+		assert.Equal(t, "Let me check the weather.", partial.Message.Text())
+		assert.Equal(t, "msg_01", partial.Message.ID)
+	}
+
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "overloaded_error", apiErr.Type)
+	}
+
+	// a connection dropped mid-stream
+	truncated := autoStreamTranscript[:strings.Index(autoStreamTranscript, "event: content_block_stop")]
+	_, err = newTestClient(t, autoStreamHandler(t, truncated), WithAutoStream()).CreateMessage(context.Background(), params)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	if assert.True(t, errors.As(err, &partial)) {
+		assert.Equal(t, "Let me check the weather.", partial.Message.Text())
+	}
+}
+
+func TestWithAutoStreamOverloadedFallback(t *testing.T) {
+	overloaded := autoStreamTranscript[:strings.Index(autoStreamTranscript, "event: content_block_start")] +
+		"event: error\n" +
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+	var requested []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		requested = append(requested, params.Model)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if params.Model == ModelClaude3Opus {
+			io.WriteString(w, overloaded)
+			return
+		}
+		io.WriteString(w, autoStreamTranscript)
+	}, WithAutoStream(), WithModelFallback(ModelClaude3Opus, ModelClaude3Haiku))
+
+	// an overloaded_error event mid-stream falls back like a 529 response
+	msg, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Opus, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ModelClaude3Opus, ModelClaude3Haiku}, requested)
+	assert.Equal(t, "Let me check the weather.", msg.Text())
+}
@@ -0,0 +1,54 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// backend adapts message requests for a cloud platform that hosts the models,
+// such as Vertex AI or Bedrock, in place of the Anthropic API.
+type backend interface {
+	// baseURL is the platform's endpoint, used unless WithBaseURL is set.
+	baseURL() string
+	// rewrite returns the URL and body for a request to path, and whether
+	// the response is streamed.
+	rewrite(baseURL, path string, body []byte) (reqURL string, newBody []byte, stream bool, err error)
+	// authorize authenticates req once its other headers are set.
+	authorize(req *http.Request, body []byte) error
+	// newDecoder splits a streamed response body into events.
+	newDecoder(r io.Reader) eventDecoder
+}
+
+// backendBody is a message request body being rewritten for a backend.
+type backendBody struct {
+	fields map[string]json.RawMessage
+	model  string
+	stream bool
+}
+
+// parseBackendBody takes the model and stream flag out of a serialized message
+// request for platform, which names the backend in errors.
+func parseBackendBody(platform string, body []byte) (*backendBody, error) {
+	b := &backendBody{}
+	if err := json.Unmarshal(body, &b.fields); err != nil {
+		return nil, fmt.Errorf("anthropic: rewriting request for %s: %w", platform, err)
+	}
+	if err := json.Unmarshal(b.fields["model"], &b.model); err != nil || b.model == "" {
+		return nil, fmt.Errorf("anthropic: %s requests must specify a model", platform)
+	}
+	if raw, ok := b.fields["stream"]; ok {
+		if err := json.Unmarshal(raw, &b.stream); err != nil {
+			return nil, fmt.Errorf("anthropic: rewriting request for %s: %w", platform, err)
+		}
+	}
+	delete(b.fields, "model")
+	return b, nil
+}
+
+// marshal serializes the body with version as its anthropic_version.
+func (b *backendBody) marshal(version string) ([]byte, error) {
+	b.fields["anthropic_version"] = json.RawMessage(`"` + version + `"`)
+	return json.Marshal(b.fields)
+}
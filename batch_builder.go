@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BatchBuilder accumulates batch requests, validating each as it is added and
+// splitting them into batches that respect the per-batch limits.
+type BatchBuilder struct {
+	client   *Client
+	requests []BatchRequest
+	sizes    []int
+	ids      map[string]struct{}
+	size     int
+	nextAuto int
+}
+
+// NewBatchBuilder returns a builder that fills unset model and max_tokens from
+// client's defaults. client may be nil.
+func NewBatchBuilder(client *Client) *BatchBuilder {
+	return &BatchBuilder{
+		client: client,
+		ids:    make(map[string]struct{}),
+	}
+}
+
+// Add queues params under customID, rejecting duplicate IDs and invalid
+// params immediately.
+func (b *BatchBuilder) Add(customID string, params MessageCreateParams) error {
+	if !customIDPattern.MatchString(customID) {
+		return fmt.Errorf("anthropic: custom_id %q must be 1-64 characters of letters, digits, '-' or '_'", customID)
+	}
+	if _, ok := b.ids[customID]; ok {
+		return fmt.Errorf("anthropic: duplicate custom_id %q", customID)
+	}
+
+	maxContentBlocks := 0
+	if b.client != nil {
+		params = b.client.applyDefaults(context.Background(), params)
+		maxContentBlocks = b.client.maxContentBlocks
+	}
+	if params.Model == "" {
+		return fmt.Errorf("anthropic: custom_id %q: model is required", customID)
+	}
+	if params.MaxTokens <= 0 {
+		return fmt.Errorf("anthropic: custom_id %q: max_tokens must be positive", customID)
+	}
+	if len(params.Messages) == 0 {
+		return fmt.Errorf("anthropic: custom_id %q: at least one message is required", customID)
+	}
+	if err := params.validate(maxContentBlocks); err != nil {
+		return fmt.Errorf("anthropic: custom_id %q: %w", customID, err)
+	}
+
+	req := BatchRequest{CustomID: customID, Params: params}
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("anthropic: custom_id %q: %w", customID, err)
+	}
+	if len(encoded) > MaxBatchSizeBytes {
+		return fmt.Errorf("anthropic: custom_id %q: request is %d bytes, larger than a whole batch may be", customID, len(encoded))
+	}
+
+	b.ids[customID] = struct{}{}
+	b.requests = append(b.requests, req)
+	b.sizes = append(b.sizes, len(encoded))
+	b.size += len(encoded)
+	return nil
+}
+
+// AddAuto queues params under a generated ID, which is returned. IDs are
+// assigned sequentially so they are stable for the same sequence of calls.
+func (b *BatchBuilder) AddAuto(params MessageCreateParams) (string, error) {
+	for {
+		b.nextAuto++
+		id := fmt.Sprintf("request-%06d", b.nextAuto)
+		if _, taken := b.ids[id]; taken {
+			continue
+		}
+		return id, b.Add(id, params)
+	}
+}
+
+// Len returns the number of queued requests.
+func (b *BatchBuilder) Len() int {
+	return len(b.requests)
+}
+
+// Size returns the encoded size in bytes of the queued requests.
+func (b *BatchBuilder) Size() int {
+	return b.size
+}
+
+// Build splits the queued requests into as few batches as the limits on
+// request count and size allow, preserving order.
+func (b *BatchBuilder) Build() [][]BatchRequest {
+	var batches [][]BatchRequest
+	start, size := 0, 0
+	for i := range b.requests {
+		if i > start && (i-start >= MaxBatchRequests || size+b.sizes[i] > MaxBatchSizeBytes) {
+			batches = append(batches, b.requests[start:i:i])
+			start, size = i, 0
+		}
+		size += b.sizes[i]
+	}
+	if start < len(b.requests) {
+		batches = append(batches, b.requests[start:len(b.requests):len(b.requests)])
+	}
+	return batches
+}
@@ -0,0 +1,71 @@
+package anthropic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchBuilderDuplicates(t *testing.T) {
+	b := NewBatchBuilder(nil)
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+
+	assert.NoError(t, b.Add("request-000002", params))
+	assert.EqualError(t, b.Add("request-000002", params), `anthropic: duplicate custom_id "request-000002"`)
+
+	id, err := b.AddAuto(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "request-000001", id)
+	id, err = b.AddAuto(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "request-000003", id, "auto IDs skip ones already taken")
+
+	assert.Equal(t, 3, b.Len())
+	assert.Error(t, b.Add("not valid!", params))
+}
+
+func TestBatchBuilderValidation(t *testing.T) {
+	client := NewClient(WithAPIKey("test"), WithDefaultModel(ModelClaude3Haiku), WithDefaultMaxTokens(512))
+	b := NewBatchBuilder(client)
+
+	assert.NoError(t, b.Add("defaults", MessageCreateParams{Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}))
+	batches := b.Build()
+	assert.Equal(t, ModelClaude3Haiku, batches[0][0].Params.Model)
+	assert.Equal(t, 512, batches[0][0].Params.MaxTokens)
+
+	b = NewBatchBuilder(nil)
+	assert.ErrorContains(t, b.Add("no-model", MessageCreateParams{MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}), "model is required")
+	assert.ErrorContains(t, b.Add("no-max-tokens", MessageCreateParams{Model: ModelClaude3Haiku, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}), "max_tokens")
+	assert.ErrorContains(t, b.Add("no-messages", MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}), "message")
+	err := b.Add("vision", MessageCreateParams{
+		Model:     ModelClaude21,
+		MaxTokens: 10,
+		Messages:  []MessageParam{{Role: RoleUser, ContentBlocks: []ContentBlockParam{NewImageBlock("image/png", "iVBORw0KGgo=")}}},
+	})
+	assert.ErrorContains(t, err, "does not support image input")
+	assert.Equal(t, 0, b.Len())
+	assert.Equal(t, 0, b.Size())
+}
+
+func TestBatchBuilderChunking(t *testing.T) {
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+
+	b := NewBatchBuilder(nil)
+	for i := 0; i < MaxBatchRequests; i++ {
+		_, err := b.AddAuto(params)
+		assert.NoError(t, err)
+	}
+	batches := b.Build()
+	assert.Len(t, batches, 1)
+	assert.Len(t, batches[0], MaxBatchRequests)
+
+	_, err := b.AddAuto(params)
+	assert.NoError(t, err)
+	batches = b.Build()
+	if assert.Len(t, batches, 2) {
+		assert.Len(t, batches[0], MaxBatchRequests)
+		assert.Len(t, batches[1], 1)
+		assert.Equal(t, "request-100001", batches[1][0].CustomID)
+		assert.NoError(t, validateBatchRequests(batches[0]))
+	}
+}
@@ -0,0 +1,158 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	BatchResultSucceeded = "succeeded"
+	BatchResultErrored   = "errored"
+	BatchResultCanceled  = "canceled"
+	BatchResultExpired   = "expired"
+)
+
+type BatchResult struct {
+	CustomID string          `json:"custom_id"`
+	Result   BatchResultBody `json:"result"`
+
+	// Line is the 1-based line of the results file the result was read from.
+	Line int `json:"-"`
+	// Err is set instead of the fields above when the line could not be
+	// decoded.
+	Err error `json:"-"`
+}
+
+type BatchResultBody struct {
+	Type    string         `json:"type"`
+	Message *Message       `json:"message,omitempty"`
+	Error   *ErrorResponse `json:"error,omitempty"`
+}
+
+type BatchResultLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *BatchResultLineError) Error() string {
+	return fmt.Sprintf("anthropic: batch results line %d: %s", e.Line, e.Err)
+}
+
+func (e *BatchResultLineError) Unwrap() error {
+	return e.Err
+}
+
+// BatchResultsStream decodes a batch's results file one line at a time, so
+// arbitrarily large result sets are never held in memory at once.
+//
+//	for stream.Next() {
+//		result := stream.Current()
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+type BatchResultsStream struct {
+	resp                *http.Response
+	reader              *bufio.Reader
+	line                int
+	current             *BatchResult
+	err                 error
+	errorMalformedLines bool
+}
+
+// GetMessageBatchResults streams the results of an ended batch from its
+// results_url, resolved against the client's base URL.
+func (c *Client) GetMessageBatchResults(ctx context.Context, batch *MessageBatch, opts ...RequestOption) (*BatchResultsStream, error) {
+	if batch.ResultsURL == nil {
+		return nil, fmt.Errorf("anthropic: results for batch %s are not available yet", batch.ID)
+	}
+
+	// the results are fetched from the client's base URL, so a proxy set with
+	// WithBaseURL is honored and credentials never go to another host
+	resultsURL, err := url.Parse(*batch.ResultsURL)
+	if err != nil || resultsURL.Path == "" {
+		return nil, fmt.Errorf("anthropic: invalid results_url %q for batch %s", *batch.ResultsURL, batch.ID)
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, resultsURL.Path, resultsURL.Query(), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	return &BatchResultsStream{
+		resp:   resp,
+		reader: bufio.NewReader(resp.Body),
+	}, nil
+}
+
+func (s *BatchResultsStream) Close() error {
+	return s.resp.Body.Close()
+}
+
+// ErrorMalformedLines makes a line that fails to decode end the stream with a
+// *BatchResultLineError. By default such lines are yielded as results with Err
+// set and decoding continues.
+func (s *BatchResultsStream) ErrorMalformedLines() {
+	s.errorMalformedLines = true
+}
+
+func (s *BatchResultsStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			s.err = err
+			return false
+		}
+		if len(line) > 0 {
+			s.line++
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err == io.EOF {
+				return false
+			}
+			continue
+		}
+
+		var result BatchResult
+		if decodeErr := json.Unmarshal(line, &result); decodeErr != nil {
+			lineErr := &BatchResultLineError{Line: s.line, Err: decodeErr}
+			if s.errorMalformedLines {
+				s.err = lineErr
+				return false
+			}
+			result = BatchResult{Err: lineErr}
+		}
+		result.Line = s.line
+		s.current = &result
+		return true
+	}
+}
+
+func (s *BatchResultsStream) Current() *BatchResult {
+	return s.current
+}
+
+func (s *BatchResultsStream) Err() error {
+	return s.err
+}
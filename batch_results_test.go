@@ -0,0 +1,167 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchResultsHandler serves testdata/batch_results.jsonl as the results of
+// msgbatch_01.
+func batchResultsHandler(t *testing.T) http.HandlerFunc {
+	fixture, err := os.ReadFile("testdata/batch_results.jsonl")
+	assert.NoError(t, err)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages/batches/msgbatch_01/results", r.URL.Path)
+		w.Header().Set("Content-Type", "application/binary")
+		w.Write(fixture)
+	}
+}
+
+func endedBatch() *MessageBatch {
+	resultsURL := "https://api.anthropic.com/v1/messages/batches/msgbatch_01/results"
+	return &MessageBatch{ID: "msgbatch_01", ProcessingStatus: BatchStatusEnded, ResultsURL: &resultsURL}
+}
+
+func TestGetMessageBatchResultsURL(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/batch_results.jsonl")
+	assert.NoError(t, err)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/files/batch_results_01/content", r.URL.Path)
+		assert.Equal(t, "sig=abc", r.URL.RawQuery)
+		w.Write(fixture)
+	})
+
+	batch := endedBatch()
+	resultsURL := "https://api.anthropic.com/v1/files/batch_results_01/content?sig=abc"
+	batch.ResultsURL = &resultsURL
+	stream, err := client.GetMessageBatchResults(context.Background(), batch)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+	assert.True(t, stream.Next())
+	assert.Equal(t, "my-first-request", stream.Current().CustomID)
+}
+
+func TestGetMessageBatchResults(t *testing.T) {
+	client := newTestClient(t, batchResultsHandler(t))
+
+	stream, err := client.GetMessageBatchResults(context.Background(), endedBatch())
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	var results []*BatchResult
+	for stream.Next() {
+		results = append(results, stream.Current())
+	}
+	assert.NoError(t, stream.Err())
+	if !assert.Len(t, results, 5) {
+		return
+	}
+
+	succeeded := results[0]
+	assert.Equal(t, "my-first-request", succeeded.CustomID)
+	assert.Equal(t, BatchResultSucceeded, succeeded.Result.Type)
+	assert.Equal(t, "Hello again! It's nice to see you.", succeeded.Result.Message.Content[0].Text)
+	assert.Equal(t, 36, succeeded.Result.Message.Usage.OutputTokens)
+
+	errored := results[1]
+	assert.Equal(t, BatchResultErrored, errored.Result.Type)
+	assert.Equal(t, "invalid_request_error", errored.Result.Error.Error.Type)
+	assert.Nil(t, errored.Result.Message)
+
+	assert.Equal(t, BatchResultCanceled, results[2].Result.Type)
+
+	var lineErr *BatchResultLineError
+	assert.ErrorAs(t, results[3].Err, &lineErr)
+	assert.Equal(t, 4, lineErr.Line)
+	assert.Equal(t, 4, results[3].Line)
+
+	assert.Equal(t, BatchResultExpired, results[4].Result.Type)
+	assert.Equal(t, "my-fourth-request", results[4].CustomID)
+	assert.Equal(t, 5, results[4].Line)
+}
+
+func TestGetMessageBatchResultsErrorMalformedLines(t *testing.T) {
+	client := newTestClient(t, batchResultsHandler(t))
+
+	stream, err := client.GetMessageBatchResults(context.Background(), endedBatch())
+	assert.NoError(t, err)
+	defer stream.Close()
+	stream.ErrorMalformedLines()
+
+	count := 0
+	for stream.Next() {
+		count++
+	}
+	assert.Equal(t, 3, count)
+	var lineErr *BatchResultLineError
+	assert.ErrorAs(t, stream.Err(), &lineErr)
+	assert.Equal(t, 4, lineErr.Line)
+}
+
+func TestGetMessageBatchResultsNotReady(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected")
+	})
+	_, err := client.GetMessageBatchResults(context.Background(), &MessageBatch{ID: "msgbatch_01", ProcessingStatus: BatchStatusInProgress})
+	assert.Error(t, err)
+}
+
+func TestCancelMessageBatchPartialResults(t *testing.T) {
+	canceled := false
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches/msgbatch_01/cancel":
+			canceled = true
+			io.WriteString(w, batchJSON(BatchStatusCanceling, MessageBatchRequestCounts{Processing: 2, Succeeded: 1}))
+		case r.URL.Path == "/v1/messages/batches/msgbatch_01" && canceled:
+			ended := strings.Replace(batchJSON(BatchStatusEnded, MessageBatchRequestCounts{Succeeded: 2, Canceled: 1}),
+				`"processing_status"`, `"results_url":"https://api.anthropic.com/v1/messages/batches/msgbatch_01/results","processing_status"`, 1)
+			io.WriteString(w, ended)
+		case r.URL.Path == "/v1/messages/batches/msgbatch_01/results":
+			io.WriteString(w, `{"custom_id":"a","result":{"type":"succeeded","message":{"id":"msg_a","type":"message","role":"assistant","content":[{"type":"text","text":"done before cancel"}]}}}`+"\n")
+			io.WriteString(w, `{"custom_id":"b","result":{"type":"succeeded","message":{"id":"msg_b","type":"message","role":"assistant","content":[{"type":"text","text":"in flight during cancel"}]}}}`+"\n")
+			io.WriteString(w, `{"custom_id":"c","result":{"type":"canceled"}}`+"\n")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	batch, err := client.CancelMessageBatch(context.Background(), "msgbatch_01")
+	assert.NoError(t, err)
+	assert.Equal(t, BatchStatusCanceling, batch.ProcessingStatus)
+
+	_, err = client.GetMessageBatchResults(context.Background(), batch)
+	assert.Error(t, err, "results are not available while canceling")
+
+	batch, err = client.PollMessageBatch(context.Background(), batch.ID, WithPollInterval(time.Millisecond))
+	assert.NoError(t, err)
+
+	stream, err := client.GetMessageBatchResults(context.Background(), batch)
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	completed := map[string]string{}
+	canceledIDs := []string{}
+	for stream.Next() {
+		result := stream.Current()
+		switch result.Result.Type {
+		case BatchResultSucceeded:
+			completed[result.CustomID] = result.Result.Message.Content[0].Text
+		case BatchResultCanceled:
+			canceledIDs = append(canceledIDs, result.CustomID)
+		}
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, map[string]string{"a": "done before cancel", "b": "in flight during cancel"}, completed)
+	assert.Equal(t, []string{"c"}, canceledIDs)
+}
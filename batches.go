@@ -0,0 +1,287 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const (
+	MaxBatchRequests  = 100_000
+	MaxBatchSizeBytes = 256 * 1024 * 1024
+)
+
+const (
+	BatchStatusInProgress = "in_progress"
+	BatchStatusCanceling  = "canceling"
+	BatchStatusEnded      = "ended"
+)
+
+var customIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type BatchRequest struct {
+	CustomID string              `json:"custom_id"`
+	Params   MessageCreateParams `json:"params"`
+}
+
+type MessageBatch struct {
+	ID                string                    `json:"id"`
+	Type              string                    `json:"type"`
+	ProcessingStatus  string                    `json:"processing_status"`
+	RequestCounts     MessageBatchRequestCounts `json:"request_counts"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	ExpiresAt         time.Time                 `json:"expires_at"`
+	EndedAt           *time.Time                `json:"ended_at"`
+	CancelInitiatedAt *time.Time                `json:"cancel_initiated_at"`
+	ArchivedAt        *time.Time                `json:"archived_at"`
+	ResultsURL        *string                   `json:"results_url"`
+}
+
+type MessageBatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+type messageBatchCreateParams struct {
+	Requests []BatchRequest `json:"requests"`
+}
+
+func (c *Client) CreateMessageBatch(ctx context.Context, requests []BatchRequest, opts ...RequestOption) (*MessageBatch, error) {
+	if err := validateBatchRequests(requests); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages/batches", nil, messageBatchCreateParams{Requests: requests}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch MessageBatch
+	_, err = c.do(req, &batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+func (c *Client) GetMessageBatch(ctx context.Context, id string, opts ...RequestOption) (*MessageBatch, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/messages/batches/"+url.PathEscape(id), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch MessageBatch
+	_, err = c.do(req, &batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// CancelMessageBatch initiates cancellation of a batch, returning it in the
+// "canceling" status. Canceling a batch that has already ended returns a
+// *ConflictError.
+//
+// Cancellation is best-effort: requests already being processed may still
+// complete. Once the batch reaches "ended", GetMessageBatchResults returns the
+// results of the requests that completed alongside "canceled" results for the
+// rest.
+func (c *Client) CancelMessageBatch(ctx context.Context, id string, opts ...RequestOption) (*MessageBatch, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages/batches/"+url.PathEscape(id)+"/cancel", nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch MessageBatch
+	_, err = c.do(req, &batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+type DeletedMessageBatch struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// DeleteMessageBatch deletes a batch and its results. Only ended batches can be
+// deleted; deleting one still in progress returns a *ConflictError and the
+// batch should be canceled first.
+func (c *Client) DeleteMessageBatch(ctx context.Context, id string, opts ...RequestOption) (*DeletedMessageBatch, error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/v1/messages/batches/"+url.PathEscape(id), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted DeletedMessageBatch
+	_, err = c.do(req, &deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
+
+func (c *Client) ListMessageBatches(ctx context.Context, params ListParams, opts ...RequestOption) (*Page[MessageBatch], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/messages/batches", params.values(), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[MessageBatch]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// ListMessageBatchesAutoPaging iterates over every batch, fetching further
+// pages as needed. Iteration stops with an error if ctx is done or any page
+// fails to load.
+func (c *Client) ListMessageBatchesAutoPaging(ctx context.Context, params ListParams, opts ...RequestOption) *PageIterator[MessageBatch] {
+	return newListIterator(ctx, params, func(ctx context.Context, params ListParams) (*Page[MessageBatch], error) {
+		return c.ListMessageBatches(ctx, params, opts...)
+	})
+}
+
+const (
+	defaultPollInterval    = 30 * time.Second
+	defaultPollMaxInterval = 5 * time.Minute
+)
+
+type pollConfig struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	progress    func(MessageBatchRequestCounts)
+}
+
+type PollOption func(*pollConfig)
+
+// WithPollInterval sets the delay between polls. Defaults to 30 seconds, which
+// is also used if interval is not positive.
+func WithPollInterval(interval time.Duration) PollOption {
+	return func(p *pollConfig) {
+		p.interval = interval
+	}
+}
+
+// WithPollMaxInterval caps the delay reached by backing off after failed
+// polls. Defaults to 5 minutes, which is also used if maxInterval is not
+// positive.
+func WithPollMaxInterval(maxInterval time.Duration) PollOption {
+	return func(p *pollConfig) {
+		p.maxInterval = maxInterval
+	}
+}
+
+// WithPollProgress registers fn to receive the request counts after every
+// successful poll.
+func WithPollProgress(fn func(MessageBatchRequestCounts)) PollOption {
+	return func(p *pollConfig) {
+		p.progress = fn
+	}
+}
+
+// PollMessageBatch polls the batch until its processing status is "ended".
+// Transient failures back off exponentially; client errors such as a missing
+// batch are returned immediately. If ctx is done first, the last batch state
+// seen is returned alongside the context's error.
+func (c *Client) PollMessageBatch(ctx context.Context, id string, opts ...PollOption) (*MessageBatch, error) {
+	cfg := pollConfig{
+		interval:    defaultPollInterval,
+		maxInterval: defaultPollMaxInterval,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	// a zero delay would poll in a tight loop
+	if cfg.interval <= 0 {
+		cfg.interval = defaultPollInterval
+	}
+	if cfg.maxInterval <= 0 {
+		cfg.maxInterval = defaultPollMaxInterval
+	}
+
+	var last *MessageBatch
+	delay := cfg.interval
+	for {
+		batch, err := c.GetMessageBatch(ctx, id)
+		switch {
+		case ctx.Err() != nil:
+			return last, ctx.Err()
+		case err != nil:
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError && apiErr.StatusCode != http.StatusTooManyRequests {
+				return last, err
+			}
+			delay = min(delay*2, cfg.maxInterval)
+		default:
+			last = batch
+			delay = cfg.interval
+			if cfg.progress != nil {
+				cfg.progress(batch.RequestCounts)
+			}
+			if batch.ProcessingStatus == BatchStatusEnded {
+				return batch, nil
+			}
+		}
+
+		if err := c.sleep(ctx, delay); err != nil {
+			return last, err
+		}
+	}
+}
+
+// WaitForBatch polls the batch every interval until its processing status is
+// "ended" and returns it. It is PollMessageBatch with a fixed interval: polling
+// backs off on transient failures and stops as soon as ctx is done. An
+// interval that is not positive means the default of 30 seconds.
+func (c *Client) WaitForBatch(ctx context.Context, id string, interval time.Duration) (*MessageBatch, error) {
+	return c.PollMessageBatch(ctx, id, WithPollInterval(interval))
+}
+
+func validateBatchRequests(requests []BatchRequest) error {
+	if len(requests) == 0 {
+		return fmt.Errorf("anthropic: batch must contain at least one request")
+	}
+	if len(requests) > MaxBatchRequests {
+		return fmt.Errorf("anthropic: batch contains %d requests, the maximum is %d", len(requests), MaxBatchRequests)
+	}
+
+	seen := make(map[string]struct{}, len(requests))
+	size := 0
+	for i, r := range requests {
+		if !customIDPattern.MatchString(r.CustomID) {
+			return fmt.Errorf("anthropic: batch request %d: custom_id %q must be 1-64 characters of letters, digits, '-' or '_'", i, r.CustomID)
+		}
+		if _, ok := seen[r.CustomID]; ok {
+			return fmt.Errorf("anthropic: batch request %d: duplicate custom_id %q", i, r.CustomID)
+		}
+		seen[r.CustomID] = struct{}{}
+
+		b, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("anthropic: batch request %d: %w", i, err)
+		}
+		size += len(b)
+	}
+	if size > MaxBatchSizeBytes {
+		return fmt.Errorf("anthropic: batch is %d bytes, the maximum is %d", size, MaxBatchSizeBytes)
+	}
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMessageBatch(t *testing.T) {
+	golden, err := os.ReadFile("testdata/batch_create_request.json")
+	assert.NoError(t, err)
+	fixture, err := os.ReadFile("testdata/batch_create_response.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/messages/batches", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, string(golden), string(body))
+		w.Write(fixture)
+	})
+
+	batch, err := client.CreateMessageBatch(context.Background(), []BatchRequest{
+		{
+			CustomID: "first-request",
+			Params: MessageCreateParams{
+				Model:     ModelClaude35Sonnet,
+				MaxTokens: 1024,
+				Messages:  []MessageParam{{Role: RoleUser, Content: "Hello, world"}},
+			},
+		},
+		{
+			CustomID: "second_request",
+			Params: MessageCreateParams{
+				Model:     ModelClaude3Haiku,
+				MaxTokens: 512,
+				System:    "Be brief.",
+				Messages:  []MessageParam{{Role: RoleUser, Content: "Hi again, friend"}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "msgbatch_013Zva2CMHLNnXjNJJKqJ2EF", batch.ID)
+	assert.Equal(t, "message_batch", batch.Type)
+	assert.Equal(t, BatchStatusInProgress, batch.ProcessingStatus)
+	assert.Equal(t, MessageBatchRequestCounts{Processing: 2}, batch.RequestCounts)
+	assert.Equal(t, time.Date(2024, 9, 24, 18, 37, 24, 100435000, time.UTC), batch.CreatedAt)
+	assert.Equal(t, time.Date(2024, 9, 25, 18, 37, 24, 100435000, time.UTC), batch.ExpiresAt)
+	assert.Nil(t, batch.EndedAt)
+	assert.Nil(t, batch.ResultsURL)
+}
+
+func TestCreateMessageBatchValidation(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("invalid batch must not be sent")
+	})
+
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+	tooMany := make([]BatchRequest, MaxBatchRequests+1)
+	for i := range tooMany {
+		tooMany[i] = BatchRequest{CustomID: fmt.Sprintf("req-%d", i), Params: params}
+	}
+
+	cases := map[string][]BatchRequest{
+		"empty":             nil,
+		"empty custom_id":   {{CustomID: "", Params: params}},
+		"invalid custom_id": {{CustomID: "has spaces", Params: params}},
+		"long custom_id":    {{CustomID: strings.Repeat("a", 65), Params: params}},
+		"duplicate":         {{CustomID: "a", Params: params}, {CustomID: "a", Params: params}},
+		"too many":          tooMany,
+	}
+	for name, requests := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.CreateMessageBatch(context.Background(), requests)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func batchJSON(status string, counts MessageBatchRequestCounts) string {
+	return fmt.Sprintf(`{"id":"msgbatch_01","type":"message_batch","processing_status":%q,"request_counts":{"processing":%d,"succeeded":%d,"errored":%d,"canceled":%d,"expired":%d},"created_at":"2024-09-24T18:37:24Z","expires_at":"2024-09-25T18:37:24Z"}`,
+		status, counts.Processing, counts.Succeeded, counts.Errored, counts.Canceled, counts.Expired)
+}
+
+func TestPollMessageBatch(t *testing.T) {
+	polls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/messages/batches/msgbatch_01", r.URL.Path)
+		polls++
+		switch polls {
+		case 1:
+			io.WriteString(w, batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{Processing: 2}))
+		case 2:
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"type":"error","error":{"type":"api_error","message":"internal"}}`)
+		case 3:
+			io.WriteString(w, batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{Processing: 1, Succeeded: 1}))
+		default:
+			io.WriteString(w, batchJSON(BatchStatusEnded, MessageBatchRequestCounts{Succeeded: 1, Errored: 1}))
+		}
+	})
+
+	var progress []MessageBatchRequestCounts
+	batch, err := client.PollMessageBatch(context.Background(), "msgbatch_01",
+		WithPollInterval(time.Millisecond),
+		WithPollProgress(func(counts MessageBatchRequestCounts) {
+			progress = append(progress, counts)
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchStatusEnded, batch.ProcessingStatus)
+	assert.Equal(t, 4, polls)
+	assert.Equal(t, []MessageBatchRequestCounts{
+		{Processing: 2},
+		{Processing: 1, Succeeded: 1},
+		{Succeeded: 1, Errored: 1},
+	}, progress)
+}
+
+func TestPollMessageBatchCanceled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{Processing: 3}))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	batch, err := client.PollMessageBatch(ctx, "msgbatch_01", WithPollInterval(time.Hour))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	if assert.NotNil(t, batch) {
+		assert.Equal(t, BatchStatusInProgress, batch.ProcessingStatus)
+		assert.Equal(t, 3, batch.RequestCounts.Processing)
+	}
+}
+
+func TestPollMessageBatchNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"batch not found"}}`)
+	})
+
+	batch, err := client.PollMessageBatch(context.Background(), "msgbatch_missing", WithPollInterval(time.Millisecond))
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Nil(t, batch)
+}
+
+func TestWaitForBatch(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	polls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages/batches/msgbatch_01", r.URL.Path)
+		polls++
+		if polls < 3 {
+			io.WriteString(w, batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{Processing: 2}))
+			return
+		}
+		io.WriteString(w, batchJSON(BatchStatusEnded, MessageBatchRequestCounts{Succeeded: 2}))
+	}, WithClock(clock))
+
+	batch, err := client.WaitForBatch(context.Background(), "msgbatch_01", 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, BatchStatusEnded, batch.ProcessingStatus)
+	assert.Equal(t, MessageBatchRequestCounts{Succeeded: 2}, batch.RequestCounts)
+	assert.Equal(t, []time.Duration{30 * time.Second, 30 * time.Second}, clock.sleeps)
+}
+
+func TestWaitForBatchDefaultInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+		polls := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			polls++
+			if polls < 2 {
+				io.WriteString(w, batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{Processing: 1}))
+				return
+			}
+			io.WriteString(w, batchJSON(BatchStatusEnded, MessageBatchRequestCounts{Succeeded: 1}))
+		}, WithClock(clock))
+
+		_, err := client.WaitForBatch(context.Background(), "msgbatch_01", interval)
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Duration{defaultPollInterval}, clock.sleeps, "interval %v", interval)
+	}
+}
+
+func TestWaitForBatchCanceled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{Processing: 1}))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	batch, err := client.WaitForBatch(ctx, "msgbatch_01", time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+	if assert.NotNil(t, batch) {
+		assert.Equal(t, BatchStatusInProgress, batch.ProcessingStatus)
+	}
+}
+
+func batchItemJSON(id string) string {
+	return strings.Replace(batchJSON(BatchStatusInProgress, MessageBatchRequestCounts{}), "msgbatch_01", id, 1)
+}
+
+func TestListMessageBatchesAutoPaging(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages/batches", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			io.WriteString(w, pageJSON(t, []string{batchItemJSON("msgbatch_a"), batchItemJSON("msgbatch_b")}, true, "msgbatch_a", "msgbatch_b"))
+		case "msgbatch_b":
+			io.WriteString(w, pageJSON(t, []string{batchItemJSON("msgbatch_c"), batchItemJSON("msgbatch_d")}, true, "msgbatch_c", "msgbatch_d"))
+		case "msgbatch_d":
+			io.WriteString(w, pageJSON(t, []string{batchItemJSON("msgbatch_e")}, false, "msgbatch_e", "msgbatch_e"))
+		}
+	})
+
+	it := client.ListMessageBatchesAutoPaging(context.Background(), ListParams{Limit: 2})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"msgbatch_a", "msgbatch_b", "msgbatch_c", "msgbatch_d", "msgbatch_e"}, ids)
+}
+
+func TestListMessageBatchesAutoPagingError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after_id") == "" {
+			io.WriteString(w, pageJSON(t, []string{batchItemJSON("msgbatch_a"), batchItemJSON("msgbatch_b")}, true, "msgbatch_a", "msgbatch_b"))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad cursor"}}`)
+	})
+
+	it := client.ListMessageBatchesAutoPaging(context.Background(), ListParams{})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.Equal(t, []string{"msgbatch_a", "msgbatch_b"}, ids)
+	var apiErr *APIError
+	if assert.ErrorAs(t, it.Err(), &apiErr) {
+		assert.Equal(t, "bad cursor", apiErr.Message)
+	}
+	assert.False(t, it.Next())
+}
+
+func TestListMessageBatchesAutoPagingCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		id := fmt.Sprintf("msgbatch_%d", requests)
+		io.WriteString(w, pageJSON(t, []string{batchItemJSON(id)}, true, id, id))
+	})
+
+	it := client.ListMessageBatchesAutoPaging(ctx, ListParams{})
+	assert.True(t, it.Next())
+	cancel()
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+	assert.Equal(t, 1, requests)
+}
+
+func TestCancelMessageBatch(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		switch r.URL.Path {
+		case "/v1/messages/batches/msgbatch_01/cancel":
+			io.WriteString(w, batchJSON(BatchStatusCanceling, MessageBatchRequestCounts{Processing: 2}))
+		case "/v1/messages/batches/msgbatch_ended/cancel":
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"Batch has already ended"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	batch, err := client.CancelMessageBatch(context.Background(), "msgbatch_01")
+	assert.NoError(t, err)
+	assert.Equal(t, BatchStatusCanceling, batch.ProcessingStatus)
+
+	_, err = client.CancelMessageBatch(context.Background(), "msgbatch_ended")
+	var conflict *ConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, "Batch has already ended", conflict.Message)
+	}
+}
+
+func TestDeleteMessageBatch(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		switch r.URL.Path {
+		case "/v1/messages/batches/msgbatch_01":
+			io.WriteString(w, `{"id":"msgbatch_01","type":"message_batch_deleted"}`)
+		case "/v1/messages/batches/msgbatch_missing":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"batch not found"}}`)
+		case "/v1/messages/batches/msgbatch_running":
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"Cannot delete a batch that has not ended"}}`)
+		}
+	})
+
+	deleted, err := client.DeleteMessageBatch(context.Background(), "msgbatch_01")
+	assert.NoError(t, err)
+	assert.Equal(t, &DeletedMessageBatch{ID: "msgbatch_01", Type: "message_batch_deleted"}, deleted)
+
+	_, err = client.DeleteMessageBatch(context.Background(), "msgbatch_missing")
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = client.DeleteMessageBatch(context.Background(), "msgbatch_running")
+	var conflict *ConflictError
+	assert.ErrorAs(t, err, &conflict)
+}
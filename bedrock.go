@@ -0,0 +1,222 @@
+package anthropic
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// bedrockAPIVersion is sent in the body of Bedrock requests in place of the
+// anthropic-version header.
+const bedrockAPIVersion = "bedrock-2023-05-31"
+
+// bedrockModelIDs maps the API's model names to Bedrock model IDs.
+var bedrockModelIDs = map[string]string{
+	ModelClaude35Sonnet20240620: "anthropic.claude-3-5-sonnet-20240620-v1:0",
+	ModelClaude3Opus20240229:    "anthropic.claude-3-opus-20240229-v1:0",
+	ModelClaude3Sonnet20240229:  "anthropic.claude-3-sonnet-20240229-v1:0",
+	ModelClaude3Haiku20240307:   "anthropic.claude-3-haiku-20240307-v1:0",
+	ModelClaude21:               "anthropic.claude-v2:1",
+	ModelClaude20:               "anthropic.claude-v2",
+	ModelClaudeInstant12:        "anthropic.claude-instant-v1",
+}
+
+// BedrockModelID returns the Bedrock model ID for model. Models without a
+// known mapping, such as Bedrock model IDs and inference profile ARNs, are
+// returned unchanged.
+func BedrockModelID(model string) string {
+	if id, ok := bedrockModelIDs[model]; ok {
+		return id
+	}
+	return model
+}
+
+type bedrockConfig struct {
+	region string
+	signer Signer
+}
+
+// WithBedrock routes message requests through AWS Bedrock in region. Requests
+// are authenticated by signer, which must sign them with SigV4 for the
+// "bedrock" service, e.g. by wrapping the AWS SDK's v4.Signer. Model names are
+// mapped with BedrockModelID and moved into the URL, the API version moves
+// into the body, and Bedrock's event stream framing is decoded, so
+// CreateMessage and StreamMessage are otherwise unchanged. Other endpoints are
+// not available on Bedrock and fail. A base URL set with WithBaseURL replaces
+// the regional Bedrock endpoint.
+func WithBedrock(region string, signer Signer) ClientOption {
+	return func(c *Client) {
+		c.backend = &bedrockConfig{region: region, signer: signer}
+	}
+}
+
+// baseURL returns the Bedrock runtime endpoint for the configured region.
+func (b *bedrockConfig) baseURL() string {
+	return fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", b.region)
+}
+
+// rewrite returns the Bedrock URL and body for a request to path with the
+// serialized body.
+func (b *bedrockConfig) rewrite(baseURL, path string, body []byte) (string, []byte, bool, error) {
+	if path != "/v1/messages" {
+		return "", nil, false, fmt.Errorf("anthropic: %s is not supported on Bedrock", path)
+	}
+
+	parsed, err := parseBackendBody("Bedrock", body)
+	if err != nil {
+		return "", nil, false, err
+	}
+	// streaming is chosen by the endpoint, and Bedrock rejects the field
+	delete(parsed.fields, "stream")
+	body, err = parsed.marshal(bedrockAPIVersion)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	action := "invoke"
+	if parsed.stream {
+		action = "invoke-with-response-stream"
+	}
+	// inference profile ARNs contain slashes, so the ID is escaped as one segment
+	reqURL, err := url.JoinPath(baseURL, "model", url.PathEscape(BedrockModelID(parsed.model)), action)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("anthropic: invalid base URL %q: %w", baseURL, err)
+	}
+	return reqURL, body, parsed.stream, nil
+}
+
+func (b *bedrockConfig) authorize(req *http.Request, body []byte) error {
+	if err := b.signer.Sign(req, body); err != nil {
+		return fmt.Errorf("anthropic: signing Bedrock request: %w", err)
+	}
+	return nil
+}
+
+func (b *bedrockConfig) newDecoder(r io.Reader) eventDecoder {
+	return &bedrockDecoder{reader: bufio.NewReader(r)}
+}
+
+// bedrockDecoder reads the AWS event stream framing of Bedrock's streamed
+// responses. Each chunk carries one of the API's events, base64-encoded, and
+// exceptions are returned as error events.
+type bedrockDecoder struct {
+	reader *bufio.Reader
+}
+
+// eventStreamPreludeLen is the size of a frame's total length, headers length
+// and prelude checksum.
+const eventStreamPreludeLen = 12
+
+func (d *bedrockDecoder) next() (sseEvent, error) {
+	prelude := make([]byte, eventStreamPreludeLen)
+	if _, err := io.ReadFull(d.reader, prelude); err != nil {
+		if errors.Is(err, io.EOF) {
+			return sseEvent{}, io.EOF
+		}
+		return sseEvent{}, fmt.Errorf("invalid event stream frame: %w", err)
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return sseEvent{}, fmt.Errorf("invalid event stream frame: prelude checksum mismatch")
+	}
+	if totalLen < eventStreamPreludeLen+4 || headersLen > totalLen-eventStreamPreludeLen-4 {
+		return sseEvent{}, fmt.Errorf("invalid event stream frame: length %d with %d bytes of headers", totalLen, headersLen)
+	}
+
+	frame := make([]byte, totalLen)
+	copy(frame, prelude)
+	if _, err := io.ReadFull(d.reader, frame[eventStreamPreludeLen:]); err != nil {
+		return sseEvent{}, fmt.Errorf("invalid event stream frame: %w", err)
+	}
+	if crc32.ChecksumIEEE(frame[:totalLen-4]) != binary.BigEndian.Uint32(frame[totalLen-4:]) {
+		return sseEvent{}, fmt.Errorf("invalid event stream frame: message checksum mismatch")
+	}
+
+	headers, err := parseEventStreamHeaders(frame[eventStreamPreludeLen : eventStreamPreludeLen+headersLen])
+	if err != nil {
+		return sseEvent{}, err
+	}
+	payload := frame[eventStreamPreludeLen+headersLen : totalLen-4]
+
+	switch headers[":message-type"] {
+	case "event":
+		if eventType := headers[":event-type"]; eventType != "chunk" {
+			return sseEvent{}, fmt.Errorf("unexpected event stream event type: %s", eventType)
+		}
+		var chunk struct {
+			Bytes string `json:"bytes"`
+		}
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			return sseEvent{}, fmt.Errorf("invalid event stream chunk: %w", err)
+		}
+		data, err := base64.StdEncoding.DecodeString(chunk.Bytes)
+		if err != nil {
+			return sseEvent{}, fmt.Errorf("invalid event stream chunk: %w", err)
+		}
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return sseEvent{}, fmt.Errorf("invalid event stream chunk: %w", err)
+		}
+		return sseEvent{Type: event.Type, Data: data}, nil
+	case "exception", "error":
+		return sseEvent{Type: string(StreamEventError), Data: fmt.Appendf(nil, "%s: %s", headers[":exception-type"], payload)}, nil
+	default:
+		return sseEvent{}, fmt.Errorf("unexpected event stream message type: %s", headers[":message-type"])
+	}
+}
+
+// parseEventStreamHeaders returns the string-valued headers of an event stream
+// frame, skipping values of other types.
+func parseEventStreamHeaders(b []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, fmt.Errorf("invalid event stream headers")
+		}
+		name := string(b[1 : 1+nameLen])
+		valueType := b[1+nameLen]
+		b = b[1+nameLen+1:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // true, false
+		case 2: // byte
+			size = 1
+		case 3: // short
+			size = 2
+		case 4: // int
+			size = 4
+		case 5, 8: // long, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // bytes, string
+			if len(b) < 2 {
+				return nil, fmt.Errorf("invalid event stream headers")
+			}
+			size = int(binary.BigEndian.Uint16(b))
+			b = b[2:]
+		default:
+			return nil, fmt.Errorf("invalid event stream header type %d", valueType)
+		}
+		if len(b) < size {
+			return nil, fmt.Errorf("invalid event stream headers")
+		}
+		if valueType == 7 {
+			headers[name] = string(b[:size])
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
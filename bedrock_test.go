@@ -0,0 +1,135 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSigV4Signer stands in for an AWS SDK signer, recording what it signed.
+type fakeSigV4Signer struct {
+	body []byte
+}
+
+func (s *fakeSigV4Signer) Sign(req *http.Request, body []byte) error {
+	s.body = body
+	req.Header.Set("X-Amz-Date", "20240601T000000Z")
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKID/20240601/us-east-1/bedrock/aws4_request")
+	return nil
+}
+
+// collectStreamedMessage reassembles the message delivered by stream.
+func collectStreamedMessage(t *testing.T, stream *MessageStream) Message {
+	t.Helper()
+	var msg Message
+	for stream.Next() {
+		switch event := stream.Event(); event.Type {
+		case StreamEventMessageStart:
+			msg = *event.Message
+		case StreamEventContentBlockStart:
+			msg.Content = append(msg.Content, *event.ContentBlock)
+		case StreamEventContentBlockDelta:
+			msg.Content[event.Index].Text += event.ContentBlock.Text
+		case StreamEventMessageDelta:
+			msg.StopReason = event.Delta.StopReason
+		}
+	}
+	assert.NoError(t, stream.Err())
+	msg.Usage = stream.Usage()
+	return msg
+}
+
+func TestBedrockCreateMessage(t *testing.T) {
+	response, err := os.ReadFile("testdata/bedrock_response.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	signer := &fakeSigV4Signer{}
+	client, requests := newRecordingTestClient(t, respondWith(response), WithBedrock("us-east-1", signer))
+	params := MessageCreateParams{
+		Model:     ModelClaude3Haiku,
+		MaxTokens: 10,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Hi"}},
+	}
+
+	msg, err := client.CreateMessage(context.Background(), params)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	directClient := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(response)
+	})
+	direct, err := directClient.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, direct, msg)
+
+	if !assert.Len(t, *requests, 1) {
+		return
+	}
+	req := (*requests)[0]
+	assert.Equal(t, "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke", req.path)
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKID/20240601/us-east-1/bedrock/aws4_request", req.header.Get("Authorization"))
+	assert.Empty(t, req.header.Get("X-API-Key"))
+	assert.Empty(t, req.header.Get("anthropic-version"))
+	assert.NotContains(t, req.body, "model")
+	assert.Equal(t, "bedrock-2023-05-31", req.body["anthropic_version"])
+
+	var signed map[string]interface{}
+	assert.NoError(t, json.Unmarshal(signer.body, &signed))
+	assert.Equal(t, req.body, signed)
+}
+
+func TestBedrockStreamMessage(t *testing.T) {
+	response, err := os.ReadFile("testdata/bedrock_stream.bin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	client, requests := newRecordingTestClient(t, respondWith(response), WithBedrock("us-east-1", &fakeSigV4Signer{}))
+	params := MessageCreateParams{
+		Model:     ModelClaude3Haiku,
+		MaxTokens: 10,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Hi"}},
+	}
+
+	stream, err := client.StreamMessage(context.Background(), params)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+	msg := collectStreamedMessage(t, stream)
+
+	direct := collectStreamedMessage(t, newTestStream(t, testTranscript))
+	assert.Equal(t, direct, msg)
+	assert.Equal(t, "Hello, world", msg.Content[0].Text)
+
+	req := (*requests)[0]
+	assert.Equal(t, "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke-with-response-stream", req.path)
+	assert.NotContains(t, req.body, "model")
+	assert.NotContains(t, req.body, "stream")
+	assert.Equal(t, "bedrock-2023-05-31", req.body["anthropic_version"])
+}
+
+func TestBedrockStreamChecksum(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/bedrock_stream.bin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	fixture[20] ^= 0xff
+
+	decoder := (&bedrockConfig{}).newDecoder(bytes.NewReader(fixture))
+	_, err = decoder.next()
+	assert.EqualError(t, err, "invalid event stream frame: message checksum mismatch")
+}
+
+func TestBedrockModelID(t *testing.T) {
+	assert.Equal(t, "anthropic.claude-3-5-sonnet-20240620-v1:0", BedrockModelID(ModelClaude35Sonnet))
+	assert.Equal(t, "anthropic.claude-v2:1", BedrockModelID(ModelClaude21))
+	arn := "arn:aws:bedrock:us-east-1:123456789012:inference-profile/us.anthropic.claude-3-haiku-20240307-v1:0"
+	assert.Equal(t, arn, BedrockModelID(arn))
+}
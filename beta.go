@@ -0,0 +1,125 @@
+package anthropic
+
+import "strings"
+
+// Beta names a beta feature, enabled with the anthropic-beta header.
+type Beta string
+
+const (
+	BetaPromptCaching       Beta = "prompt-caching-2024-07-31"
+	BetaExtendedCacheTTL    Beta = "extended-cache-ttl-2025-04-11"
+	BetaPDFs                Beta = "pdfs-2024-09-25"
+	BetaMessageBatches      Beta = "message-batches-2024-09-24"
+	BetaTokenCounting       Beta = "token-counting-2024-11-01"
+	BetaComputerUse20241022 Beta = "computer-use-2024-10-22"
+	BetaComputerUse20250124 Beta = "computer-use-2025-01-24"
+	BetaFilesAPI            Beta = "files-api-2025-04-14"
+	BetaMCPClient           Beta = "mcp-client-2025-04-04"
+	BetaOutput128k          Beta = "output-128k-2025-02-19"
+	BetaInterleavedThinking Beta = "interleaved-thinking-2025-05-14"
+	BetaTokenEfficientTools Beta = "token-efficient-tools-2025-02-19"
+)
+
+// WithBetas enables betas for this call, in addition to any set with
+// WithBetaVersion or inferred from the request.
+func WithBetas(betas ...Beta) RequestOption {
+	return func(r *requestConfig) {
+		for _, beta := range betas {
+			r.betas = append(r.betas, string(beta))
+		}
+	}
+}
+
+// WithoutBetaInference stops message requests from enabling the betas their
+// features require, leaving only those set explicitly. By default a request
+// using, e.g., a one hour cache TTL or an MCP server gets the matching beta.
+func WithoutBetaInference() ClientOption {
+	return func(c *Client) {
+		c.noBetaInference = true
+	}
+}
+
+// computerUseBetas maps the versioned types of the computer use tools to the
+// beta they require.
+var computerUseBetas = map[string]Beta{
+	"computer_20241022":    BetaComputerUse20241022,
+	"text_editor_20241022": BetaComputerUse20241022,
+	"bash_20241022":        BetaComputerUse20241022,
+	"computer_20250124":    BetaComputerUse20250124,
+	"text_editor_20250124": BetaComputerUse20250124,
+	"bash_20250124":        BetaComputerUse20250124,
+}
+
+// inferBetas returns the betas required by the features params uses.
+func inferBetas(params MessageCreateParams) []Beta {
+	var betas []Beta
+	add := func(beta Beta) {
+		for _, b := range betas {
+			if b == beta {
+				return
+			}
+		}
+		betas = append(betas, beta)
+	}
+	cache := func(cc *CacheControl) {
+		if cc != nil && cc.TTL == "1h" {
+			add(BetaExtendedCacheTTL)
+		}
+	}
+	var blocks func([]ContentBlockParam)
+	blocks = func(content []ContentBlockParam) {
+		for _, block := range content {
+			cache(block.CacheControl)
+			if block.Source == nil {
+				continue
+			}
+			if block.Source.Type == "file" {
+				add(BetaFilesAPI)
+			}
+			blocks(block.Source.Content)
+		}
+	}
+
+	for _, tool := range params.Tools {
+		if beta, ok := computerUseBetas[tool.Type]; ok {
+			add(beta)
+		}
+		cache(tool.CacheControl)
+	}
+	for _, msg := range params.Messages {
+		blocks(msg.ContentBlocks)
+	}
+	if len(params.MCPServers) > 0 {
+		add(BetaMCPClient)
+	}
+	return betas
+}
+
+// betaOptions returns an option enabling the betas params requires, if any
+// and unless inference is disabled, followed by opts.
+func (c *Client) betaOptions(params MessageCreateParams, opts []RequestOption) []RequestOption {
+	if c.noBetaInference {
+		return opts
+	}
+	betas := inferBetas(params)
+	if len(betas) == 0 {
+		return opts
+	}
+	return prependOptions(WithBetas(betas...), opts)
+}
+
+// joinBetas returns the anthropic-beta header value for betas, each of which
+// may itself be a comma-separated list, without duplicates.
+func joinBetas(betas []string) string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, list := range betas {
+		for _, beta := range strings.Split(list, ",") {
+			if beta = strings.TrimSpace(beta); beta != "" && !seen[beta] {
+				seen[beta] = true
+				unique = append(unique, beta)
+			}
+		}
+	}
+	return strings.Join(unique, ",")
+}
@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetaInference(t *testing.T) {
+	var header string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("anthropic-beta")
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[]}`)
+	}
+	client := newTestClient(t, handler, WithBetaVersion(string(BetaMCPClient)))
+
+	user := func(blocks ...ContentBlockParam) []MessageParam {
+		return []MessageParam{{Role: RoleUser, Content: "Hi", ContentBlocks: blocks}}
+	}
+	longCache := NewTextBlock("a long, reused prompt")
+	longCache.CacheControl = CacheEphemeral("1h")
+	shortCache := NewTextBlock("a prompt")
+	shortCache.CacheControl = CacheEphemeral("")
+
+	cases := map[string]struct {
+		params MessageCreateParams
+		opts   []RequestOption
+		want   string
+	}{
+		"no features": {
+			params: MessageCreateParams{Messages: user()},
+			want:   "mcp-client-2025-04-04",
+		},
+		"1h cache TTL": {
+			params: MessageCreateParams{Messages: user(longCache)},
+			want:   "mcp-client-2025-04-04,extended-cache-ttl-2025-04-11",
+		},
+		"5m cache TTL": {
+			params: MessageCreateParams{Messages: user(shortCache)},
+			want:   "mcp-client-2025-04-04",
+		},
+		"cached tool": {
+			params: MessageCreateParams{Messages: user(), Tools: []Tool{{Name: "search", InputSchema: map[string]interface{}{"type": "object"}, CacheControl: CacheEphemeral("1h")}}},
+			want:   "mcp-client-2025-04-04,extended-cache-ttl-2025-04-11",
+		},
+		"MCP server, deduplicated": {
+			params: MessageCreateParams{Messages: user(), MCPServers: []MCPServer{{Type: "url", URL: "https://mcp.example.com/sse", Name: "example"}}},
+			want:   "mcp-client-2025-04-04",
+		},
+		"files API": {
+			params: MessageCreateParams{Messages: user(NewFileDocument("file_011CNha8iCJcU1wXNR6q4V8w"))},
+			want:   "mcp-client-2025-04-04,files-api-2025-04-14",
+		},
+		"computer use": {
+			params: MessageCreateParams{Messages: user(), Tools: []Tool{
+				{Type: "computer_20241022", Name: "computer", DisplayWidthPx: 1024, DisplayHeightPx: 768},
+				{Type: "bash_20241022", Name: "bash"},
+			}},
+			want: "mcp-client-2025-04-04,computer-use-2024-10-22",
+		},
+		"explicit betas first": {
+			params: MessageCreateParams{Messages: user(NewFileDocument("file_01"))},
+			opts:   []RequestOption{WithBetas(BetaFilesAPI, BetaOutput128k)},
+			want:   "mcp-client-2025-04-04,files-api-2025-04-14,output-128k-2025-02-19",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.params.Model = ModelClaude35Sonnet
+			tc.params.MaxTokens = 10
+			_, err := client.CreateMessage(context.Background(), tc.params, tc.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, header)
+		})
+	}
+
+	// streamed requests infer betas too
+	stream, err := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("anthropic-beta")
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	}).StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 10, Messages: user(longCache)})
+	if assert.NoError(t, err) {
+		stream.Close()
+	}
+	assert.Equal(t, "extended-cache-ttl-2025-04-11", header)
+}
+
+func TestWithoutBetaInference(t *testing.T) {
+	var header string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("anthropic-beta")
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[]}`)
+	}, WithoutBetaInference())
+
+	params := MessageCreateParams{
+		Model:      ModelClaude35Sonnet,
+		MaxTokens:  10,
+		Messages:   []MessageParam{{Role: RoleUser, ContentBlocks: []ContentBlockParam{NewFileDocument("file_01")}}},
+		MCPServers: []MCPServer{{Type: "url", URL: "https://mcp.example.com/sse", Name: "example"}},
+	}
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Empty(t, header)
+
+	_, err = client.CreateMessage(context.Background(), params, WithBetas(BetaMCPClient))
+	assert.NoError(t, err)
+	assert.Equal(t, "mcp-client-2025-04-04", header)
+}
@@ -6,36 +6,103 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
-	defaultBaseURL      = "https://api.anthropic.com"
-	defaultTimeout      = 600 * time.Second
-	defaultMaxRetries   = 2
-	defaultUserAgent    = "anthropic-go/0.1.0"
-	defaultContentType  = "application/json"
-	defaultAccept       = "application/json"
-	defaultStreamAccept = "text/event-stream"
-	defaultAPIVersion   = "2023-06-01"
-	defaultBetaVersion  = ""
+	defaultBaseURL       = "https://api.anthropic.com"
+	defaultTimeout       = 600 * time.Second
+	defaultMaxRetries    = 2
+	defaultUserAgent     = "anthropic-go/0.1.0"
+	defaultContentType   = "application/json"
+	defaultAccept        = "application/json"
+	defaultStreamAccept  = "text/event-stream"
+	defaultAPIVersion    = "2023-06-01"
+	defaultBetaVersion   = ""
+	defaultMinRetryDelay = 500 * time.Millisecond
+	defaultMaxRetryDelay = 8 * time.Second
 )
 
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	529, // overloaded
+}
+
 type Client struct {
-	apiKey       string
-	authToken    string
-	baseURL      string
-	httpClient   *http.Client
-	maxRetries   int
-	userAgent    string
-	timeout      time.Duration
-	streamAccept string
-	apiVersion   string
-	betaVersion  string
+	apiKey        string
+	authToken     string
+	baseURL       string
+	baseURLSet    bool
+	basePath      string
+	httpClient    *http.Client
+	maxRetries    int
+	userAgent     string
+	timeout       time.Duration
+	streamAccept  string
+	apiVersion    string
+	betaVersion   string
+	signer        Signer
+	wrapTransport func(next http.RoundTripper) http.RoundTripper
+	emitPings     bool
+	keyProvider   KeyProvider
+	tokenProvider func(ctx context.Context) (string, error)
+	adminKey      string
+	backend       backend
+	clock         Clock
+	ignoreEnv     bool
+	dryRun        *log.Logger
+	slogger       *slog.Logger
+
+	defaultModel     string
+	defaultMaxTokens int
+	defaultMetadata  map[string]string
+	defaultSystem    string
+	defaultSystemFn  func(ctx context.Context) string
+	defaultStops     []string
+	contextWindows   map[string]int
+	fallbackModels   []string
+	singleFlight     *singleflight.Group
+	maxContentBlocks int
+	headerTransform  func(key, value string) (string, string)
+	autoStream       bool
+	noBetaInference  bool
+
+	responseValidator func(*Message) error
+
+	thinkingBudget       int
+	thinkingAnswerTokens int
+
+	rateLimitObserver func(RateLimitEvent)
+
+	retryableStatusCodes []int
+	minRetryDelay        time.Duration
+	maxRetryDelay        time.Duration
+}
+
+// Signer is invoked for every request after the body has been serialized and
+// before it is sent, allowing gateways that require request signing. Streamed
+// uploads are signed with a nil body.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// KeyProvider supplies the API key for each request, allowing keys to be
+// fetched from a secrets manager and rotated without recreating the client.
+type KeyProvider interface {
+	GetAPIKey(ctx context.Context) (string, error)
 }
 
 type ClientOption func(*Client)
@@ -46,15 +113,52 @@ func WithAPIKey(apiKey string) ClientOption {
 	}
 }
 
+// WithAPIKeyProvider sets a provider consulted for the API key on every
+// request. It takes precedence over WithAPIKey.
+func WithAPIKeyProvider(provider KeyProvider) ClientOption {
+	return func(c *Client) {
+		c.keyProvider = provider
+	}
+}
+
+// WithAdminKey sets the admin API key used for the Admin API endpoints, such
+// as ListAPIKeys. Without one, those endpoints are called with the regular API
+// key.
+func WithAdminKey(adminKey string) ClientOption {
+	return func(c *Client) {
+		c.adminKey = adminKey
+	}
+}
+
 func WithAuthToken(authToken string) ClientOption {
 	return func(c *Client) {
 		c.authToken = authToken
 	}
 }
 
+// WithBaseURL sets the URL requests are sent to. It takes precedence over the
+// ANTHROPIC_BASE_URL environment variable.
 func WithBaseURL(baseURL string) ClientOption {
 	return func(c *Client) {
 		c.baseURL = baseURL
+		c.baseURLSet = true
+	}
+}
+
+// WithoutEnvironment stops NewClient and ClientBuilder from falling back to
+// environment variables for settings left unset, for hermetic setups such as
+// tests and multi-tenant servers that must not pick up ambient credentials.
+func WithoutEnvironment() ClientOption {
+	return func(c *Client) {
+		c.ignoreEnv = true
+	}
+}
+
+// WithBaseURLPath sets a path prefix inserted between the base URL and each
+// endpoint's path, for gateways that mount the API under a subpath.
+func WithBaseURLPath(prefix string) ClientOption {
+	return func(c *Client) {
+		c.basePath = prefix
 	}
 }
 
@@ -70,6 +174,15 @@ func WithMaxRetries(maxRetries int) ClientOption {
 	}
 }
 
+// WithRetryableStatusCodes replaces the set of response status codes that are
+// retried, up to the limit set by WithMaxRetries. The default set is 429, 500,
+// 502, 503 and 529.
+func WithRetryableStatusCodes(codes ...int) ClientOption {
+	return func(c *Client) {
+		c.retryableStatusCodes = codes
+	}
+}
+
 func WithUserAgent(userAgent string) ClientOption {
 	return func(c *Client) {
 		c.userAgent = userAgent
@@ -100,7 +213,219 @@ func WithBetaVersion(version string) ClientOption {
 	}
 }
 
+// WithRequestSigner sets a Signer that signs each request just before it is
+// sent. It runs on every attempt, including retries and the resend after a
+// credential refresh, so a signature that covers a timestamp is never stale.
+func WithRequestSigner(signer Signer) ClientOption {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
+
+// WithRoundTripper wraps the transport used for requests without replacing the
+// rest of the configured http.Client (timeouts, cookie jar, redirect policy).
+// wrap receives the client's current transport, such as a proxy or mTLS
+// transport set on the client passed to WithHTTPClient, or
+// http.DefaultTransport if it has none, and returns middleware that delegates
+// to it. The client passed to WithHTTPClient, or http.DefaultClient, is copied
+// rather than modified.
+func WithRoundTripper(wrap func(next http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.wrapTransport = wrap
+	}
+}
+
+// WithHeaderTransformer rewrites every response header with fn before the
+// client reads any of them, e.g. to map a proxy's X-Anthropic-Request-Id back
+// to request-id. fn is called once per header value; returning an empty key
+// drops the value. Keys are canonicalized afterwards.
+func WithHeaderTransformer(fn func(key, value string) (string, string)) ClientOption {
+	return func(c *Client) {
+		c.headerTransform = fn
+	}
+}
+
+// WithEmitPings makes streams return ping events from Recv rather than
+// skipping them, for callers that use them as keep-alive signals.
+func WithEmitPings() ClientOption {
+	return func(c *Client) {
+		c.emitPings = true
+	}
+}
+
+// WithDefaultModel sets the model used by requests that don't specify one.
+func WithDefaultModel(model string) ClientOption {
+	return func(c *Client) {
+		c.defaultModel = model
+	}
+}
+
+// WithDefaultMaxTokens sets max_tokens for requests that don't specify it.
+func WithDefaultMaxTokens(maxTokens int) ClientOption {
+	return func(c *Client) {
+		c.defaultMaxTokens = maxTokens
+	}
+}
+
+// WithDefaultSystem sets the system prompt for message requests that don't
+// specify one.
+func WithDefaultSystem(system string) ClientOption {
+	return func(c *Client) {
+		c.defaultSystem = system
+	}
+}
+
+// WithDefaultSystemFn is like WithDefaultSystem but builds the prompt for each
+// request, e.g. to include the current date. It takes precedence over
+// WithDefaultSystem.
+func WithDefaultSystemFn(fn func(ctx context.Context) string) ClientOption {
+	return func(c *Client) {
+		c.defaultSystemFn = fn
+	}
+}
+
+// WithDefaultMetadata sets metadata, such as user_id, merged into the metadata
+// of every message request. Keys set on a request take precedence.
+func WithDefaultMetadata(metadata map[string]string) ClientOption {
+	return func(c *Client) {
+		c.defaultMetadata = metadata
+	}
+}
+
+// WithDefaultStopSequences sets stop sequences, such as a sentinel token,
+// added to those of every message request. Duplicates are dropped; the merged
+// list must not exceed MaxStopSequences.
+func WithDefaultStopSequences(sequences ...string) ClientOption {
+	return func(c *Client) {
+		c.defaultStops = append([]string(nil), sequences...)
+	}
+}
+
+// WithModelContextWindow sets the context window of model in tokens, for
+// models missing from the built-in table or to override it.
+func WithModelContextWindow(model string, window int) ClientOption {
+	return func(c *Client) {
+		// copied so clients made with WithOptions don't share the map
+		windows := make(map[string]int, len(c.contextWindows)+1)
+		for m, w := range c.contextWindows {
+			windows[m] = w
+		}
+		windows[model] = window
+		c.contextWindows = windows
+	}
+}
+
+// WithMaxContentBlocks makes message requests fail with
+// ErrTooManyContentBlocks, before anything is sent, when a message has more
+// than n content blocks. This catches runaway loops building content, which
+// the API would otherwise reject with a large 400 response; 1000 is a sensible
+// limit. By default the count is not checked.
+func WithMaxContentBlocks(n int) ClientOption {
+	return func(c *Client) {
+		c.maxContentBlocks = n
+	}
+}
+
+// WithResponseValidator passes each message CreateMessage receives to fn
+// before returning it, e.g. to enforce a content policy or scrub PII. fn may
+// modify the message; an error from it is returned to the caller in place of
+// the message.
+func WithResponseValidator(fn func(msg *Message) error) ClientOption {
+	return func(c *Client) {
+		c.responseValidator = fn
+	}
+}
+
+// RequestOption overrides client settings for a single call.
+type RequestOption func(*requestConfig)
+
+type requestConfig struct {
+	apiVersion   string
+	betas        []string
+	responseMeta *ResponseMeta
+	admin        bool
+	model        string
+
+	progress         func(ProgressInfo)
+	progressInterval time.Duration
+	autoStream       bool
+
+	// credentialProvider is set once a provider has supplied the credentials.
+	credentialProvider bool
+	// dryRun leaves the request unauthenticated and unsigned, as it is only
+	// logged.
+	dryRun bool
+}
+
+// optionsConfig returns the settings opts make, for calls that need them
+// before newRequest.
+func optionsConfig(opts []RequestOption) requestConfig {
+	var cfg requestConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+type requestConfigKey struct{}
+
+// requestConfigFrom returns the config newRequest attached to ctx, if any.
+func requestConfigFrom(ctx context.Context) *requestConfig {
+	cfg, _ := ctx.Value(requestConfigKey{}).(*requestConfig)
+	return cfg
+}
+
+// WithRequestAPIVersion sends version as the anthropic-version header for this
+// call only, in place of the client's version.
+func WithRequestAPIVersion(version string) RequestOption {
+	return func(r *requestConfig) {
+		r.apiVersion = version
+	}
+}
+
+// withModel records the model a request is for, for observers.
+func withModel(model string) RequestOption {
+	return func(r *requestConfig) {
+		r.model = model
+	}
+}
+
+// prependOptions returns opts led by first without modifying the caller's
+// slice.
+func prependOptions(first RequestOption, opts []RequestOption) []RequestOption {
+	return append([]RequestOption{first}, opts...)
+}
+
+// withDryRun builds the request for WithDryRun, without consulting the
+// credential providers or the Signer.
+func withDryRun() RequestOption {
+	return func(r *requestConfig) {
+		r.dryRun = true
+	}
+}
+
+// withAdminKey authenticates the request with the admin key, if one is set.
+func withAdminKey() RequestOption {
+	return func(r *requestConfig) {
+		r.admin = true
+	}
+}
+
+// NewClient returns a client configured by opts. Settings an option leaves
+// unset fall back to the environment, then to the defaults: the API key to
+// ANTHROPIC_API_KEY, the auth token to ANTHROPIC_AUTH_TOKEN, the admin key to
+// ANTHROPIC_ADMIN_KEY and the base URL to ANTHROPIC_BASE_URL. A base URL from
+// the environment is not used with WithVertex or WithBedrock. WithoutEnvironment
+// disables the fallback.
 func NewClient(opts ...ClientOption) *Client {
+	c := newClient(opts...)
+	c.loadEnv()
+	return c
+}
+
+// newClient applies opts over the defaults without consulting the
+// environment.
+func newClient(opts ...ClientOption) *Client {
 	c := &Client{
 		baseURL:      defaultBaseURL,
 		httpClient:   http.DefaultClient,
@@ -110,80 +435,388 @@ func NewClient(opts ...ClientOption) *Client {
 		streamAccept: defaultStreamAccept,
 		apiVersion:   defaultAPIVersion,
 		betaVersion:  defaultBetaVersion,
+		clock:        RealClock(),
+
+		retryableStatusCodes: defaultRetryableStatusCodes,
+		minRetryDelay:        defaultMinRetryDelay,
+		maxRetryDelay:        defaultMaxRetryDelay,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	c.applyRoundTripper()
+	return c
+}
+
+// loadEnv falls back to the environment for credentials and the base URL left
+// unset, unless WithoutEnvironment was given.
+func (c *Client) loadEnv() {
+	if c.ignoreEnv {
+		return
+	}
+	if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" && !c.baseURLSet && c.backend == nil {
+		c.baseURL = baseURL
+	}
 	if c.apiKey == "" {
 		c.apiKey = os.Getenv("ANTHROPIC_API_KEY")
 	}
 	if c.authToken == "" {
 		c.authToken = os.Getenv("ANTHROPIC_AUTH_TOKEN")
 	}
+	if c.adminKey == "" {
+		c.adminKey = os.Getenv("ANTHROPIC_ADMIN_KEY")
+	}
+}
 
-	return c
+// WithOptions returns a copy of the client with opts applied on top of its
+// current configuration.
+func (c *Client) WithOptions(opts ...ClientOption) *Client {
+	clone := *c
+	clone.wrapTransport = nil
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	clone.applyRoundTripper()
+	return &clone
 }
 
-func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
 
-	var req *http.Request
-	var err error
-	if body != nil {
-		req, err = http.NewRequestWithContext(ctx, method, url, jsonBody(body))
-	} else {
-		req, err = http.NewRequestWithContext(ctx, method, url, nil)
+func (c *Client) applyRoundTripper() {
+	if c.wrapTransport != nil && c.httpClient != nil {
+		httpClient := *c.httpClient
+		next := httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		httpClient.Transport = c.wrapTransport(next)
+		c.httpClient = &httpClient
+	}
+}
+
+// NewClientE is like NewClient but reports invalid configuration instead of
+// deferring the failure to the first request.
+func NewClientE(opts ...ClientOption) (*Client, error) {
+	c := NewClient(opts...)
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) validate() error {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("anthropic: invalid base URL %q: %w", c.baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("anthropic: invalid base URL %q: must be an absolute http(s) URL", c.baseURL)
+	}
+	if c.httpClient == nil {
+		return fmt.Errorf("anthropic: http client must not be nil")
+	}
+	if c.timeout < 0 {
+		return fmt.Errorf("anthropic: timeout must not be negative, got %s", c.timeout)
+	}
+	if c.maxRetries < 0 {
+		return fmt.Errorf("anthropic: max retries must not be negative, got %d", c.maxRetries)
+	}
+	return nil
+}
+
+// newRequest builds a request for path, joined onto the client's base URL and
+// path prefix so slashes between them don't matter, with query as its query
+// string. opts override client settings for this request only.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}, opts ...RequestOption) (*http.Request, error) {
+	cfg := requestConfig{
+		apiVersion: c.apiVersion,
 	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	reqURL, err := url.JoinPath(c.baseURL, c.basePath, path)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: invalid base URL %q: %w", c.baseURL, err)
+	}
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	contentType := defaultContentType
+	var bodyBytes []byte
+	var bodyReader io.Reader
+	if stream, ok := body.(streamBody); ok {
+		// streamed bodies, such as multipart uploads, are never held in memory
+		contentType = stream.contentType
+		bodyReader = stream.Reader
+	} else if raw, ok := body.(json.RawMessage); ok {
+		// pre-serialized bodies are sent verbatim; json.Marshal would compact them
+		bodyBytes = raw
+		bodyReader = bytes.NewReader(bodyBytes)
+	} else if body != nil {
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	stream := false
+	if c.backend != nil {
+		baseURL := c.baseURL
+		if !c.baseURLSet {
+			baseURL = c.backend.baseURL()
+		}
+		reqURL, bodyBytes, stream, err = c.backend.rewrite(baseURL, path, bodyBytes)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	// send reads the config back from the request's context
+	ctx = context.WithValue(ctx, requestConfigKey{}, &cfg)
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", defaultContentType)
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", defaultAccept)
+	if stream {
+		req.Header.Set("Accept", c.streamAccept)
+	}
 	req.Header.Set("User-Agent", c.userAgent)
-	req.Header.Set("anthropic-version", c.apiVersion)
+	if c.backend == nil {
+		req.Header.Set("anthropic-version", cfg.apiVersion)
+	}
+	betas := cfg.betas
 	if c.betaVersion != "" {
-		req.Header.Set("anthropic-beta", c.apiVersion)
+		betas = append([]string{c.betaVersion}, betas...)
+	}
+	if header := joinBetas(betas); header != "" {
+		req.Header.Set("anthropic-beta", header)
+	}
+
+	if cfg.dryRun {
+		return req, nil
+	}
+	if c.backend != nil {
+		if err := c.backend.authorize(req, bodyBytes); err != nil {
+			return nil, err
+		}
+		return c.sign(req, bodyBytes)
 	}
 
-	if c.apiKey != "" {
-		req.Header.Set("X-API-Key", c.apiKey)
-	} else if c.authToken != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.authToken))
+	if err := c.authenticate(ctx, req, &cfg); err != nil {
+		return nil, err
 	}
+	return c.sign(req, bodyBytes)
+}
 
+// sign passes req to the configured Signer, if any.
+func (c *Client) sign(req *http.Request, body []byte) (*http.Request, error) {
+	if c.signer != nil {
+		if err := c.signer.Sign(req, body); err != nil {
+			return nil, fmt.Errorf("anthropic: signing request: %w", err)
+		}
+	}
 	return req, nil
 }
 
+// resign rewinds req's body so it can be sent again and, if a Signer is
+// configured, signs it afresh, since a signature may cover a timestamp.
+func (c *Client) resign(req *http.Request) error {
+	if req.GetBody == nil {
+		if c.signer == nil {
+			return nil
+		}
+		_, err := c.sign(req, nil)
+		return err
+	}
+
+	var body []byte
+	if c.signer != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		if body, err = io.ReadAll(r); err != nil {
+			return err
+		}
+	}
+	var err error
+	if req.Body, err = req.GetBody(); err != nil {
+		return err
+	}
+	_, err = c.sign(req, body)
+	return err
+}
+
+// streamBody is a request body sent from r as it is read rather than
+// serialized up front. Requests with a streamed body are not retried.
+type streamBody struct {
+	io.Reader
+	contentType string
+}
+
 func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := c.httpClient.Do(req)
+	started := c.clock.Now()
+	resp, err := c.decode(req, v)
+	c.logRequest(req, resp, v, err, started)
+	return resp, err
+}
+
+// decode sends req and decodes the response body into v, unless v is nil.
+func (c *Client) decode(req *http.Request, v interface{}) (*http.Response, error) {
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("anthropic: %s - %s", resp.Status, string(bodyBytes))
+		return nil, newAPIError(resp)
 	}
 
 	if v != nil {
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
 			return nil, err
 		}
+		if err := json.Unmarshal(body, v); err != nil {
+			// e.g. a gateway answering with an HTML page
+			return nil, &APIError{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				RequestID:  resp.Header.Get("request-id"),
+				Body:       string(body),
+			}
+		}
 	}
 
 	return resp, nil
 }
 
-func jsonBody(v interface{}) io.Reader {
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(v); err != nil {
-		panic(err)
+// send performs req, retrying responses with a retryable status code, and once
+// more with refreshed credentials if those from a provider are rejected. The
+// final response is returned regardless of its status.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.sendWithRetries(req)
+	if err != nil {
+		return nil, err
+	}
+	if canRefreshCredentials(req, resp) {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := c.refreshCredentials(req); err != nil {
+			return nil, err
+		}
+		if resp, err = c.sendWithRetries(req); err != nil {
+			return nil, err
+		}
+	}
+	if cfg := requestConfigFrom(req.Context()); cfg != nil && cfg.responseMeta != nil {
+		cfg.responseMeta.fill(resp)
+	}
+	return resp, nil
+}
+
+func (c *Client) sendWithRetries(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		c.transformHeaders(resp)
+		// a body that can't be replayed can't be retried
+		replayable := req.Body == nil || req.GetBody != nil
+		if attempt >= c.maxRetries || !c.isRetryable(resp.StatusCode) || !replayable {
+			c.observeRateLimit(req, resp, attempt, 0)
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		delay := c.retryDelay(attempt)
+		if after := c.parseRetryAfter(resp.Header.Get("Retry-After")); after > 0 {
+			delay = after
+		}
+		c.observeRateLimit(req, resp, attempt, delay)
+
+		if err := c.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if err := c.resign(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// transformHeaders applies the WithHeaderTransformer function to resp's
+// headers.
+func (c *Client) transformHeaders(resp *http.Response) {
+	if c.headerTransform == nil {
+		return
+	}
+	header := make(http.Header, len(resp.Header))
+	for key, values := range resp.Header {
+		for _, value := range values {
+			if key, value := c.headerTransform(key, value); key != "" {
+				header.Add(key, value)
+			}
+		}
+	}
+	resp.Header = header
+}
+
+func (c *Client) isRetryable(statusCode int) bool {
+	for _, code := range c.retryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay backs off exponentially from minRetryDelay, capped at
+// maxRetryDelay.
+func (c *Client) retryDelay(attempt int) time.Duration {
+	delay := c.minRetryDelay << attempt
+	if delay > c.maxRetryDelay || delay <= 0 {
+		return c.maxRetryDelay
+	}
+	return delay
+}
+
+// parseRetryAfter returns the delay requested by a Retry-After header, given
+// either in seconds or as an HTTP date, capped at maxRetryDelay. It returns 0
+// when the header is absent, unparseable or already in the past, in which case
+// the exponential backoff applies.
+func (c *Client) parseRetryAfter(header string) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = date.Sub(c.clock.Now())
+	}
+
+	if delay <= 0 {
+		return 0
 	}
-	return &buf
+	return min(delay, c.maxRetryDelay)
 }
 
 func idempotencyKey() string {
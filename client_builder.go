@@ -0,0 +1,76 @@
+package anthropic
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ClientBuilder configures a Client step by step and validates the result
+// before constructing it, unlike NewClient which accepts any combination of
+// options.
+type ClientBuilder struct {
+	opts []ClientOption
+}
+
+func NewClientBuilder() *ClientBuilder {
+	return &ClientBuilder{}
+}
+
+func (b *ClientBuilder) SetAPIKey(apiKey string) *ClientBuilder {
+	return b.With(WithAPIKey(apiKey))
+}
+
+func (b *ClientBuilder) SetAuthToken(authToken string) *ClientBuilder {
+	return b.With(WithAuthToken(authToken))
+}
+
+func (b *ClientBuilder) SetBaseURL(baseURL string) *ClientBuilder {
+	return b.With(WithBaseURL(baseURL))
+}
+
+func (b *ClientBuilder) SetHTTPClient(httpClient *http.Client) *ClientBuilder {
+	return b.With(WithHTTPClient(httpClient))
+}
+
+func (b *ClientBuilder) SetMaxRetries(maxRetries int) *ClientBuilder {
+	return b.With(WithMaxRetries(maxRetries))
+}
+
+func (b *ClientBuilder) SetTimeout(timeout time.Duration) *ClientBuilder {
+	return b.With(WithTimeout(timeout))
+}
+
+func (b *ClientBuilder) SetUserAgent(userAgent string) *ClientBuilder {
+	return b.With(WithUserAgent(userAgent))
+}
+
+func (b *ClientBuilder) SetAPIVersion(version string) *ClientBuilder {
+	return b.With(WithApiVersion(version))
+}
+
+func (b *ClientBuilder) SetBetaVersion(version string) *ClientBuilder {
+	return b.With(WithBetaVersion(version))
+}
+
+// With applies options that have no dedicated setter.
+func (b *ClientBuilder) With(opts ...ClientOption) *ClientBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build validates the configuration and returns the client. Setting both an
+// API key and an auth token is rejected as ambiguous; credentials and the
+// base URL left unset fall back to the environment as with NewClient.
+func (b *ClientBuilder) Build() (*Client, error) {
+	c := newClient(b.opts...)
+	if c.apiKey != "" && c.authToken != "" {
+		return nil, fmt.Errorf("anthropic: only one of API key and auth token may be set")
+	}
+	c.loadEnv()
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,60 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientBuilder(t *testing.T) {
+	var gotKey, gotVersion string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-API-Key")
+		gotVersion = r.Header.Get("anthropic-version")
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	})
+
+	built, err := NewClientBuilder().
+		SetAPIKey("built-key").
+		SetBaseURL(client.baseURL).
+		SetTimeout(time.Minute).
+		SetMaxRetries(0).
+		SetAPIVersion("2025-01-01").
+		With(WithDefaultModel(ModelClaude3Haiku)).
+		Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, time.Minute, built.timeout)
+	assert.Equal(t, 0, built.maxRetries)
+
+	_, err = built.CreateMessage(context.Background(), MessageCreateParams{MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "built-key", gotKey)
+	assert.Equal(t, "2025-01-01", gotVersion)
+}
+
+func TestClientBuilderValidation(t *testing.T) {
+	invalid := map[string]*ClientBuilder{
+		"key and token":    NewClientBuilder().SetAPIKey("key").SetAuthToken("token"),
+		"negative timeout": NewClientBuilder().SetAPIKey("key").SetTimeout(-time.Second),
+		"negative retries": NewClientBuilder().SetAPIKey("key").SetMaxRetries(-1),
+		"relative URL":     NewClientBuilder().SetAPIKey("key").SetBaseURL("/v1"),
+		"nil http client":  NewClientBuilder().SetAPIKey("key").SetHTTPClient(nil),
+	}
+	for name, builder := range invalid {
+		t.Run(name, func(t *testing.T) {
+			client, err := builder.Build()
+			assert.Error(t, err)
+			assert.Nil(t, client)
+		})
+	}
+
+	t.Setenv("ANTHROPIC_AUTH_TOKEN", "env-token")
+	client, err := NewClientBuilder().SetAPIKey("key").Build()
+	assert.NoError(t, err, "credentials from the environment don't conflict with explicit ones")
+	assert.Equal(t, "key", client.apiKey)
+}
@@ -0,0 +1,611 @@
+package anthropic
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hmacSigner struct {
+	secret []byte
+}
+
+func (s hmacSigner) Sign(req *http.Request, body []byte) error {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(body)
+	req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+func TestRequestSigner(t *testing.T) {
+	secret := []byte("gateway-secret")
+
+	var gotSignature string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Signature")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Ok"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRequestSigner(hmacSigner{secret: secret}),
+	)
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{
+		Model:     ModelClaude3Haiku,
+		MaxTokens: 10,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(gotBody)
+	assert.NotEmpty(t, gotBody)
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), gotSignature)
+}
+
+// countingSigner signs like hmacSigner and numbers its signatures in an
+// X-Signed header.
+type countingSigner struct {
+	hmacSigner
+	signed int
+}
+
+func (s *countingSigner) Sign(req *http.Request, body []byte) error {
+	s.signed++
+	req.Header.Set("X-Signed", strconv.Itoa(s.signed))
+	return s.hmacSigner.Sign(req, body)
+}
+
+func TestRequestSignerRetries(t *testing.T) {
+	secret := []byte("gateway-secret")
+	signer := &countingSigner{hmacSigner: hmacSigner{secret: secret}}
+	var signatures []string
+	statuses := []int{529, http.StatusOK}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Signature"))
+		signatures = append(signatures, r.Header.Get("X-Signed"))
+		status := statuses[0]
+		statuses = statuses[1:]
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}, WithRequestSigner(signer))
+	client.minRetryDelay = time.Millisecond
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, signatures)
+}
+
+func TestNewRequestBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		basePath string
+		want     string
+	}{
+		{"https://proxy.example.com", "", "https://proxy.example.com/v1/messages"},
+		{"https://proxy.example.com/", "", "https://proxy.example.com/v1/messages"},
+		{"https://gw.internal/anthropic", "", "https://gw.internal/anthropic/v1/messages"},
+		{"https://gw.internal/anthropic/", "", "https://gw.internal/anthropic/v1/messages"},
+		{"https://gw.internal/teams/ml/anthropic/", "", "https://gw.internal/teams/ml/anthropic/v1/messages"},
+		{"https://gw.internal", "/anthropic", "https://gw.internal/anthropic/v1/messages"},
+		{"https://gw.internal/", "anthropic/", "https://gw.internal/anthropic/v1/messages"},
+		{"https://gw.internal/teams", "/anthropic", "https://gw.internal/teams/anthropic/v1/messages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL+" "+tt.basePath, func(t *testing.T) {
+			client := NewClient(WithAPIKey("test"), WithBaseURL(tt.baseURL), WithBaseURLPath(tt.basePath))
+			req, err := client.newRequest(context.Background(), http.MethodPost, "/v1/messages", nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, req.URL.String())
+		})
+	}
+
+	client := NewClient(WithAPIKey("test"), WithBaseURL("https://gw.internal/anthropic/"))
+	req, err := client.newRequest(context.Background(), http.MethodGet, "/v1/models/"+url.PathEscape("a/b"), url.Values{"limit": {"2"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://gw.internal/anthropic/v1/models/a%2Fb?limit=2", req.URL.String())
+
+	_, err = NewClient(WithAPIKey("test"), WithBaseURL("http://[::1")).newRequest(context.Background(), http.MethodGet, "/v1/models", nil, nil)
+	assert.Error(t, err)
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...ClientOption) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(append([]ClientOption{WithAPIKey("test"), WithBaseURL(server.URL)}, opts...)...)
+}
+
+// recordedRequest is a request received by the server of a client from
+// newRecordingTestClient. body is its JSON body, if any, and params the same
+// body decoded as message parameters.
+type recordedRequest struct {
+	path   string
+	header http.Header
+	body   map[string]interface{}
+	params MessageCreateParams
+}
+
+// newRecordingTestClient is newTestClient with a server that records each
+// request before respond answers it.
+func newRecordingTestClient(t *testing.T, respond func(w http.ResponseWriter, req recordedRequest), opts ...ClientOption) (*Client, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		req := recordedRequest{path: r.URL.EscapedPath(), header: r.Header.Clone()}
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if len(data) > 0 {
+			assert.NoError(t, json.Unmarshal(data, &req.body))
+			// only meaningful for message requests; other bodies may not fit
+			json.Unmarshal(data, &req.params)
+		}
+		requests = append(requests, req)
+		respond(w, req)
+	}, opts...)
+	return client, &requests
+}
+
+// respondWith returns a respond function for newRecordingTestClient that
+// answers every request with body.
+func respondWith(body []byte) func(http.ResponseWriter, recordedRequest) {
+	return func(w http.ResponseWriter, req recordedRequest) {
+		w.Write(body)
+	}
+}
+
+// pageJSON returns a list response holding items, each a JSON object.
+func pageJSON(t *testing.T, items []string, hasMore bool, firstID, lastID string) string {
+	t.Helper()
+	for _, item := range items {
+		assert.True(t, json.Valid([]byte(item)), item)
+	}
+	return fmt.Sprintf(`{"data":[%s],"has_more":%t,"first_id":%q,"last_id":%q}`, strings.Join(items, ","), hasMore, firstID, lastID)
+}
+
+func TestNewClientE(t *testing.T) {
+	client, err := NewClientE(WithAPIKey("test"), WithBaseURL("https://proxy.example.com/anthropic"))
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+
+	invalid := map[string]ClientOption{
+		"relative base URL":   WithBaseURL("/v1"),
+		"unparsable base URL": WithBaseURL("http://[::1"),
+		"non-http base URL":   WithBaseURL("ftp://example.com"),
+		"nil http client":     WithHTTPClient(nil),
+		"negative timeout":    WithTimeout(-time.Second),
+		"negative retries":    WithMaxRetries(-1),
+	}
+	for name, opt := range invalid {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewClientE(WithAPIKey("test"), opt)
+			assert.Error(t, err)
+			assert.Nil(t, client)
+		})
+	}
+}
+
+type headerRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Middleware", "yes")
+	return rt.next.RoundTrip(req)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithRoundTripper(t *testing.T) {
+	var gotHeader, gotTransport string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Middleware")
+		gotTransport = r.Header.Get("X-Transport")
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}))
+	defer server.Close()
+
+	wrap := func(next http.RoundTripper) http.RoundTripper {
+		return headerRoundTripper{next: next}
+	}
+	httpClient := &http.Client{Timeout: time.Minute}
+	client := NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithRoundTripper(wrap),
+		WithHTTPClient(httpClient),
+	)
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "yes", gotHeader)
+
+	// the caller's client is left untouched while its settings carry over
+	assert.Nil(t, httpClient.Transport)
+	assert.Equal(t, time.Minute, client.httpClient.Timeout)
+	assert.Nil(t, http.DefaultClient.Transport)
+
+	// a custom transport on the caller's client is wrapped, not replaced
+	custom := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("X-Transport", "custom")
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	client = NewClient(
+		WithAPIKey("test"),
+		WithBaseURL(server.URL),
+		WithHTTPClient(custom),
+		WithRoundTripper(wrap),
+	)
+	gotHeader = ""
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "yes", gotHeader)
+	assert.Equal(t, "custom", gotTransport)
+}
+
+func TestWithHeaderTransformer(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	attempts := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("X-Anthropic-Request-Id", fmt.Sprintf("req_%d", attempts))
+		w.Header().Set("X-Proxy-Retry-After", "7")
+		w.Header().Set("X-Proxy-Trace", "abc")
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","error":{"type":"not_found_error","message":"model not found"}}`))
+		}
+	}, WithClock(clock), WithHeaderTransformer(func(key, value string) (string, string) {
+		switch key {
+		case "X-Anthropic-Request-Id":
+			return "request-id", value
+		case "X-Proxy-Trace":
+			return "", ""
+		}
+		return strings.TrimPrefix(key, "X-Proxy-"), value
+	}))
+
+	var meta ResponseMeta
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}, WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, "req_2", meta.RequestID)
+	assert.Equal(t, []time.Duration{7 * time.Second}, clock.sleeps)
+
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}, WithResponseMeta(&meta))
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "req_3", apiErr.RequestID)
+	}
+	assert.Empty(t, meta.Header.Get("X-Proxy-Trace"))
+	assert.Equal(t, "7", meta.Header.Get("Retry-After"))
+}
+
+type rotatingKeyProvider struct {
+	keys  []string
+	calls int
+}
+
+func (p *rotatingKeyProvider) GetAPIKey(ctx context.Context) (string, error) {
+	if p.calls >= len(p.keys) {
+		return "", errors.New("vault unavailable")
+	}
+	key := p.keys[p.calls]
+	p.calls++
+	return key, nil
+}
+
+func TestWithAPIKeyProvider(t *testing.T) {
+	var gotKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKeys = append(gotKeys, r.Header.Get("X-API-Key"))
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}))
+	defer server.Close()
+
+	provider := &rotatingKeyProvider{keys: []string{"key-1", "key-2"}}
+	client := NewClient(WithAPIKey("static"), WithBaseURL(server.URL), WithAPIKeyProvider(provider))
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.ErrorContains(t, err, "vault unavailable")
+	assert.Equal(t, []string{"key-1", "key-2"}, gotKeys)
+
+	client = NewClient(WithBaseURL(server.URL), WithAPIKeyProvider(StaticKeyProvider("static-key")))
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "static-key", gotKeys[2])
+}
+
+func TestWithRetryableStatusCodes(t *testing.T) {
+	statuses := []int{}
+	respond := func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+			return
+		}
+		w.Write([]byte(`{"type":"error","error":{"type":"error","message":"failed"}}`))
+	}
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	client := newTestClient(t, respond, WithRetryableStatusCodes(http.StatusRequestTimeout), WithMaxRetries(2))
+	client.minRetryDelay = time.Millisecond
+
+	statuses = []int{http.StatusRequestTimeout, http.StatusRequestTimeout, http.StatusOK}
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	statuses = []int{http.StatusBadRequest, http.StatusOK}
+	_, err = client.CreateMessage(context.Background(), params)
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Len(t, statuses, 1)
+
+	// a default code is no longer retried once the set is replaced
+	statuses = []int{529, http.StatusOK}
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.Error(t, err)
+	assert.Len(t, statuses, 1)
+
+	client = newTestClient(t, respond, WithRetryableStatusCodes(http.StatusRequestTimeout), WithMaxRetries(1))
+	client.minRetryDelay = time.Millisecond
+
+	statuses = []int{http.StatusRequestTimeout, http.StatusRequestTimeout, http.StatusOK}
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusRequestTimeout, apiErr.StatusCode)
+	assert.Len(t, statuses, 1)
+}
+
+func TestDefaultRetries(t *testing.T) {
+	var bodies []string
+	statuses := []int{529, http.StatusTooManyRequests, http.StatusOK}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		status := statuses[0]
+		statuses = statuses[1:]
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	})
+	client.minRetryDelay = time.Millisecond
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 3)
+	assert.Equal(t, bodies[0], bodies[2])
+	assert.NotEmpty(t, bodies[2])
+}
+
+func TestWithRequestAPIVersion(t *testing.T) {
+	var versions []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		versions = append(versions, r.Header.Get("anthropic-version"))
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	})
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), params, WithRequestAPIVersion("2025-01-01"))
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{defaultAPIVersion, "2025-01-01", defaultAPIVersion}, versions)
+}
+
+func TestBetaHeader(t *testing.T) {
+	client := NewClient(WithAPIKey("test"), WithBetaVersion("prompt-caching-2024-07-31"))
+
+	req, err := client.newRequest(context.Background(), http.MethodGet, "/v1/models", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "prompt-caching-2024-07-31", req.Header.Get("anthropic-beta"))
+
+	req, err = client.newRequest(context.Background(), http.MethodGet, "/v1/files", nil, nil, WithBetas(BetaFilesAPI))
+	assert.NoError(t, err)
+	assert.Equal(t, "prompt-caching-2024-07-31,"+string(BetaFilesAPI), req.Header.Get("anthropic-beta"))
+
+	req, err = NewClient(WithAPIKey("test")).newRequest(context.Background(), http.MethodGet, "/v1/models", nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, req.Header.Get("anthropic-beta"))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	client := NewClient(WithAPIKey("test"))
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"absent", "", 0},
+		{"seconds", "3", 3 * time.Second},
+		{"seconds with whitespace", " 2 ", 2 * time.Second},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"seconds above max", "120", defaultMaxRetryDelay},
+		{"http date", time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat), 5 * time.Second},
+		{"http date above max", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), defaultMaxRetryDelay},
+		{"past http date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+		{"unparseable", "soon", 0},
+		{"fractional seconds", "1.5", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := client.parseRetryAfter(tt.header)
+			// HTTP dates have second precision
+			assert.InDelta(t, tt.want, got, float64(time.Second))
+			assert.LessOrEqual(t, got, defaultMaxRetryDelay)
+		})
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	var times []time.Time
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	})
+	client.minRetryDelay = time.Millisecond
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, times, 2) {
+		assert.GreaterOrEqual(t, times[1].Sub(times[0]), time.Second)
+	}
+}
+
+func TestBaseURLEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		opts []ClientOption
+		want string
+	}{
+		{"default", "", nil, defaultBaseURL},
+		{"env", "https://gw.staging.example.com", nil, "https://gw.staging.example.com"},
+		{"option", "", []ClientOption{WithBaseURL("https://proxy.example.com")}, "https://proxy.example.com"},
+		{"option over env", "https://gw.staging.example.com", []ClientOption{WithBaseURL("https://proxy.example.com")}, "https://proxy.example.com"},
+		{"option set to default over env", "https://gw.staging.example.com", []ClientOption{WithBaseURL(defaultBaseURL)}, defaultBaseURL},
+		{"without environment", "https://gw.staging.example.com", []ClientOption{WithoutEnvironment()}, defaultBaseURL},
+		{"without environment with option", "https://gw.staging.example.com", []ClientOption{WithoutEnvironment(), WithBaseURL("https://proxy.example.com")}, "https://proxy.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ANTHROPIC_BASE_URL", tt.env)
+			assert.Equal(t, tt.want, NewClient(tt.opts...).baseURL)
+
+			client, err := NewClientBuilder().With(tt.opts...).Build()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, client.baseURL)
+		})
+	}
+}
+
+func TestWithoutEnvironment(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "ambient-key")
+	t.Setenv("ANTHROPIC_AUTH_TOKEN", "ambient-token")
+	t.Setenv("ANTHROPIC_ADMIN_KEY", "ambient-admin-key")
+
+	client := NewClient()
+	assert.Equal(t, "ambient-key", client.apiKey)
+	assert.Equal(t, "ambient-token", client.authToken)
+	assert.Equal(t, "ambient-admin-key", client.adminKey)
+
+	client = NewClient(WithAPIKey("explicit-key"))
+	assert.Equal(t, "explicit-key", client.apiKey)
+
+	client = NewClient(WithoutEnvironment())
+	assert.Empty(t, client.apiKey)
+	assert.Empty(t, client.authToken)
+	assert.Empty(t, client.adminKey)
+
+	client = NewClient(WithoutEnvironment(), WithAPIKey("explicit-key"))
+	assert.Equal(t, "explicit-key", client.apiKey)
+}
+
+func TestBaseURLEnvironmentIgnoredByBackends(t *testing.T) {
+	t.Setenv("ANTHROPIC_BASE_URL", "https://gw.staging.example.com")
+	client := NewClient(WithVertex("my-project", "us-east5", TokenSourceFunc(func(context.Context) (string, error) {
+		return "token", nil
+	})))
+
+	req, err := client.newRequest(context.Background(), http.MethodPost, "/v1/messages", nil, MessageCreateParams{Model: "claude-3-haiku@20240307"})
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east5-aiplatform.googleapis.com", req.URL.Host)
+}
+
+const nginxBadGateway = `<html>
+<head><title>502 Bad Gateway</title></head>
+<body>
+<center><h1>502 Bad Gateway</h1></center>
+<hr><center>nginx/1.25.3</center>
+</body>
+</html>
+` + "<!-- a padding to disable MSIE and Chrome friendly error page -->\n" +
+	"<!-- a padding to disable MSIE and Chrome friendly error page -->\n" +
+	"<!-- a padding to disable MSIE and Chrome friendly error page -->\n"
+
+func TestHTMLErrorBody(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		io.WriteString(w, nginxBadGateway)
+	}, WithMaxRetries(0))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	var apiErr *APIError
+	if !assert.ErrorAs(t, err, &apiErr) {
+		return
+	}
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Empty(t, apiErr.Type)
+	assert.Equal(t, nginxBadGateway, apiErr.Body)
+	msg := err.Error()
+	assert.True(t, strings.HasPrefix(msg, "anthropic: 502 Bad Gateway - <html> <head><title>502 Bad Gateway</title></head> <body>"), msg)
+	assert.True(t, strings.HasSuffix(msg, "..."), msg)
+	assert.Len(t, []rune(strings.TrimPrefix(msg, "anthropic: 502 Bad Gateway - ")), maxBodySnippet+len("..."))
+}
+
+func TestHTMLSuccessBody(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html><body>Please sign in to the corporate proxy</body></html>")
+	})
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	var apiErr *APIError
+	if !assert.ErrorAs(t, err, &apiErr) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, apiErr.StatusCode)
+	assert.Empty(t, apiErr.Type)
+	assert.Equal(t, "<html><body>Please sign in to the corporate proxy</body></html>", apiErr.Body)
+	assert.EqualError(t, err, "anthropic: 200 OK - <html><body>Please sign in to the corporate proxy</body></html>")
+}
@@ -0,0 +1,58 @@
+package anthropic
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for retry backoff, batch polling and event
+// timestamps. Tests can substitute one that advances instantly.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// RealClock returns a Clock backed by the time package.
+func RealClock() Clock {
+	return realClock{}
+}
+
+// WithClock sets the clock the client waits on and reads the time from.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// sleep waits for d on the client's clock, returning ctx's error if ctx is done
+// first.
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	if _, ok := c.clock.(realClock); ok {
+		// a timer can be stopped, unlike a sleeping goroutine
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.clock.Sleep(d)
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock advances instantly when slept on, recording each sleep.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+}
+
+func TestWithClockRetryBackoff(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	responses := []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) { w.WriteHeader(529) },
+		func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+		func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", start.Add(time.Minute+3*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		func(w http.ResponseWriter) {
+			w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+		},
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		responses[0](w)
+		responses = responses[1:]
+	}, WithClock(clock), WithMaxRetries(3))
+	client.maxRetryDelay = time.Hour
+
+	var events []RateLimitEvent
+	client = client.WithOptions(WithRateLimitObserver(func(event RateLimitEvent) {
+		events = append(events, event)
+	}))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, responses)
+	// the Retry-After date is measured from the clock, 1.5s after the start
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second, time.Minute + 1500*time.Millisecond}, clock.sleeps)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, start.Add(1500*time.Millisecond), events[0].Time)
+	}
+}
+
+func TestClientSleepCanceled(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	client := NewClient(WithClock(blockingClock{block}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, client.sleep(ctx, time.Second), context.Canceled)
+	assert.ErrorIs(t, NewClient().sleep(ctx, time.Hour), context.Canceled)
+}
+
+// blockingClock sleeps until its channel is closed.
+type blockingClock struct {
+	block chan struct{}
+}
+
+func (c blockingClock) Now() time.Time        { return time.Time{} }
+func (c blockingClock) Sleep(d time.Duration) { <-c.block }
@@ -0,0 +1,29 @@
+package anthropic
+
+import (
+	"context"
+	"sync"
+)
+
+// CompareModels sends params to model1 and model2 concurrently, e.g. to
+// compare their answers to the same prompt, and returns both results once both
+// requests have finished. A failure of one request does not cancel the other;
+// cancelling ctx cancels both.
+func CompareModels(ctx context.Context, client *Client, params MessageCreateParams, model1, model2 string) (msg1, msg2 *Message, err1, err2 error) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p := params
+		p.Model = model1
+		msg1, err1 = client.CreateMessage(ctx, p)
+	}()
+	go func() {
+		defer wg.Done()
+		p := params
+		p.Model = model2
+		msg2, err2 = client.CreateMessage(ctx, p)
+	}()
+	wg.Wait()
+	return msg1, msg2, err1, err2
+}
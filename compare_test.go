@@ -0,0 +1,67 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareModels(t *testing.T) {
+	// each request waits for the other, so they must be in flight together
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		arrived.Done()
+		arrived.Wait()
+
+		if params.Model == ModelClaude3Opus {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad request"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"msg_01","type":"message","role":"assistant","model":%q,"content":[{"type":"text","text":"Ok"}]}`, params.Model)
+	}, WithMaxRetries(0))
+
+	params := MessageCreateParams{MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+	msg1, msg2, err1, err2 := CompareModels(context.Background(), client, params, ModelClaude3Haiku, ModelClaude3Opus)
+	assert.NoError(t, err1)
+	assert.Equal(t, ModelClaude3Haiku, msg1.Model)
+	assert.Nil(t, msg2)
+	var apiErr *APIError
+	if assert.ErrorAs(t, err2, &apiErr) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	}
+	assert.Empty(t, params.Model)
+}
+
+func TestCompareModelsCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	params := MessageCreateParams{MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+	msg1, msg2, err1, err2 := CompareModels(ctx, client, params, ModelClaude3Haiku, ModelClaude3Opus)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Nil(t, msg1)
+	assert.Nil(t, msg2)
+	assert.ErrorIs(t, err1, context.DeadlineExceeded)
+	assert.ErrorIs(t, err2, context.DeadlineExceeded)
+}
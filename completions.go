@@ -0,0 +1,135 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Turn markers for the legacy Text Completions prompt format.
+const (
+	HumanPrompt = "\n\nHuman:"
+	AIPrompt    = "\n\nAssistant:"
+)
+
+type CompletionCreateParams struct {
+	Model             string            `json:"model"`
+	Prompt            string            `json:"prompt"`
+	MaxTokensToSample int               `json:"max_tokens_to_sample"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	StopSequences     []string          `json:"stop_sequences,omitempty"`
+	Stream            bool              `json:"stream,omitempty"`
+	Temperature       float64           `json:"temperature,omitempty"`
+	TopK              int               `json:"top_k,omitempty"`
+	TopP              float64           `json:"top_p,omitempty"`
+}
+
+type Completion struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	Completion string  `json:"completion"`
+	StopReason string  `json:"stop_reason"`
+	Stop       *string `json:"stop"`
+	Model      string  `json:"model"`
+}
+
+// BuildPrompt renders messages in the alternating Human/Assistant format
+// expected by the Text Completions API, ending with an open assistant turn
+// unless the last message is already from the assistant.
+func BuildPrompt(messages ...MessageParam) string {
+	var prompt strings.Builder
+	for _, msg := range messages {
+		if msg.Role == RoleAssistant {
+			prompt.WriteString(AIPrompt)
+		} else {
+			prompt.WriteString(HumanPrompt)
+		}
+		prompt.WriteString(" ")
+		prompt.WriteString(msg.Content)
+	}
+	if len(messages) == 0 || messages[len(messages)-1].Role != RoleAssistant {
+		prompt.WriteString(AIPrompt)
+	}
+	return prompt.String()
+}
+
+// CreateCompletion calls the legacy /v1/complete endpoint.
+func (c *Client) CreateCompletion(ctx context.Context, params CompletionCreateParams, opts ...RequestOption) (*Completion, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/complete", nil, params, prependOptions(withModel(params.Model), opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var completion Completion
+	_, err = c.do(req, &completion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &completion, nil
+}
+
+// StreamCompletion calls the legacy /v1/complete endpoint with streaming
+// enabled.
+func (c *Client) StreamCompletion(ctx context.Context, params CompletionCreateParams, opts ...RequestOption) (*CompletionStream, error) {
+	params.Stream = true
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/complete", nil, params, prependOptions(withModel(params.Model), opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.sendStream(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompletionStream{
+		resp:    resp,
+		decoder: newSSEDecoder(resp.Body),
+	}, nil
+}
+
+// CompletionStream reads the events of a streamed legacy completion. Each
+// event carries the next piece of the completion text; the last one also
+// carries the stop reason.
+type CompletionStream struct {
+	resp    *http.Response
+	decoder *sseDecoder
+	done    bool
+}
+
+func (s *CompletionStream) Close() error {
+	return s.resp.Body.Close()
+}
+
+// Recv returns the next completion event, skipping pings, and io.EOF once the
+// completion has stopped.
+func (s *CompletionStream) Recv() (*Completion, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+
+	for {
+		event, err := s.decoder.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch event.Type {
+		case "completion":
+			var completion Completion
+			if err := json.Unmarshal(event.Data, &completion); err != nil {
+				return nil, err
+			}
+			s.done = completion.StopReason != ""
+			return &completion, nil
+		case "error":
+			return nil, newStreamError(event.Data)
+		default:
+			// pings and unknown events carry no completion text
+		}
+	}
+}
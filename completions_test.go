@@ -0,0 +1,131 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPrompt(t *testing.T) {
+	assert.Equal(t, "\n\nHuman: Hi\n\nAssistant:", BuildPrompt(MessageParam{Role: RoleUser, Content: "Hi"}))
+	assert.Equal(t, "\n\nHuman: Hi\n\nAssistant: Hello", BuildPrompt(
+		MessageParam{Role: RoleUser, Content: "Hi"},
+		MessageParam{Role: RoleAssistant, Content: "Hello"},
+	))
+}
+
+func TestCreateCompletion(t *testing.T) {
+	golden, err := os.ReadFile("testdata/completion_request.json")
+	assert.NoError(t, err)
+	fixture, err := os.ReadFile("testdata/completion_response.json")
+	assert.NoError(t, err)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/complete", r.URL.Path)
+		assert.Equal(t, "test", r.Header.Get("X-API-Key"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, string(golden), string(body))
+		w.Write(fixture)
+	})
+
+	completion, err := client.CreateCompletion(context.Background(), CompletionCreateParams{
+		Model: ModelClaude21,
+		Prompt: BuildPrompt(
+			MessageParam{Role: RoleUser, Content: "Hello there"},
+			MessageParam{Role: RoleAssistant, Content: "Hi! How can I help?"},
+			MessageParam{Role: RoleUser, Content: "Tell me a joke"},
+		),
+		MaxTokensToSample: 256,
+		Metadata:          map[string]string{"user_id": "user-123"},
+		StopSequences:     []string{HumanPrompt},
+		Temperature:       0.5,
+		TopK:              40,
+		TopP:              0.9,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "compl_01XNJbqNcw3pzLM2L9pEwhWq", completion.ID)
+	assert.Equal(t, "completion", completion.Type)
+	assert.Equal(t, " Why don't scientists trust atoms? Because they make up everything!", completion.Completion)
+	assert.Equal(t, "stop_sequence", completion.StopReason)
+	if assert.NotNil(t, completion.Stop) {
+		assert.Equal(t, HumanPrompt, *completion.Stop)
+	}
+	assert.Equal(t, ModelClaude21, completion.Model)
+}
+
+func TestStreamCompletion(t *testing.T) {
+	transcript := "event: completion\n" +
+		"data: {\"type\":\"completion\",\"id\":\"compl_01\",\"completion\":\" Why\",\"stop_reason\":null,\"model\":\"claude-2.1\"}\n\n" +
+		"event: ping\n" +
+		"data: {\"type\": \"ping\"}\n\n" +
+		"event: completion\n" +
+		"data: {\"type\":\"completion\",\"id\":\"compl_01\",\"completion\":\" did the chicken\",\"stop_reason\":null,\"model\":\"claude-2.1\"}\n\n" +
+		"event: completion\n" +
+		"data: {\"type\":\"completion\",\"id\":\"compl_01\",\"completion\":\" cross the road?\",\"stop_reason\":\"stop_sequence\",\"stop\":\"\\n\\nHuman:\",\"model\":\"claude-2.1\"}\n\n"
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/complete", r.URL.Path)
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		var params CompletionCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		assert.True(t, params.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, transcript)
+	})
+
+	stream, err := client.StreamCompletion(context.Background(), CompletionCreateParams{
+		Model:             ModelClaude21,
+		Prompt:            BuildPrompt(MessageParam{Role: RoleUser, Content: "Tell me a joke"}),
+		MaxTokensToSample: 256,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	var text strings.Builder
+	var last *Completion
+	for {
+		completion, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		text.WriteString(completion.Completion)
+		last = completion
+	}
+
+	assert.Equal(t, " Why did the chicken cross the road?", text.String())
+	assert.Equal(t, "stop_sequence", last.StopReason)
+	assert.Equal(t, HumanPrompt, *last.Stop)
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestStreamCompletionError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	})
+
+	stream, err := client.StreamCompletion(context.Background(), CompletionCreateParams{Model: ModelClaude21, MaxTokensToSample: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	assert.ErrorContains(t, err, "overloaded_error")
+}
@@ -0,0 +1,198 @@
+package anthropic
+
+import "encoding/json"
+
+// ContentBlockType identifies the kind of a content block on the wire.
+type ContentBlockType string
+
+const (
+	ContentBlockTypeText       ContentBlockType = "text"
+	ContentBlockTypeImage      ContentBlockType = "image"
+	ContentBlockTypeDocument   ContentBlockType = "document"
+	ContentBlockTypeToolUse    ContentBlockType = "tool_use"
+	ContentBlockTypeToolResult ContentBlockType = "tool_result"
+	ContentBlockTypeThinking   ContentBlockType = "thinking"
+)
+
+type ContentBlockParam struct {
+	Type         ContentBlockType `json:"type"`
+	Text         string           `json:"text,omitempty"`
+	Source       *ContentSource   `json:"source,omitempty"`
+	Title        string           `json:"title,omitempty"`
+	Citations    *CitationsConfig `json:"citations,omitempty"`
+	CacheControl *CacheControl    `json:"cache_control,omitempty"`
+
+	// Set on blocks of an assistant message sent back to the model; see
+	// Message.Param.
+	Thinking  string          `json:"thinking,omitempty"`
+	Signature string          `json:"signature,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+}
+
+type ContentSource struct {
+	Type      string              `json:"type"`
+	MediaType string              `json:"media_type,omitempty"`
+	Data      string              `json:"data,omitempty"`
+	Content   []ContentBlockParam `json:"content,omitempty"`
+	// FileID refers to a file uploaded with UploadFile, for the "file" type.
+	FileID string `json:"file_id,omitempty"`
+}
+
+// CacheControl marks the end of a prompt prefix to cache.
+type CacheControl struct {
+	Type string `json:"type"`
+	// TTL is how long the prefix stays cached, "5m" by default or "1h".
+	TTL string `json:"ttl,omitempty"`
+}
+
+// CacheEphemeral returns a CacheControl caching the prefix for ttl, or for
+// the default five minutes if ttl is empty.
+func CacheEphemeral(ttl string) *CacheControl {
+	return &CacheControl{Type: "ephemeral", TTL: ttl}
+}
+
+type CitationsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+func NewTextBlock(text string) ContentBlockParam {
+	return ContentBlockParam{Type: ContentBlockTypeText, Text: text}
+}
+
+// NewImageBlock returns an image block from base64 encoded data of the given
+// media type, e.g. "image/png".
+func NewImageBlock(mediaType, data string) ContentBlockParam {
+	return ContentBlockParam{
+		Type: ContentBlockTypeImage,
+		Source: &ContentSource{
+			Type:      "base64",
+			MediaType: mediaType,
+			Data:      data,
+		},
+	}
+}
+
+// NewPDFBlock returns a document block from base64 encoded PDF data.
+func NewPDFBlock(data string) ContentBlockParam {
+	return ContentBlockParam{
+		Type: ContentBlockTypeDocument,
+		Source: &ContentSource{
+			Type:      "base64",
+			MediaType: "application/pdf",
+			Data:      data,
+		},
+	}
+}
+
+// NewFileDocument returns a document block referring to a file uploaded with
+// UploadFile.
+func NewFileDocument(fileID string) ContentBlockParam {
+	return ContentBlockParam{
+		Type:   ContentBlockTypeDocument,
+		Source: &ContentSource{Type: "file", FileID: fileID},
+	}
+}
+
+// NewTextDocument returns a document block holding plain text, for grounding
+// a response in a source the model can cite.
+func NewTextDocument(title, text string) ContentBlockParam {
+	return ContentBlockParam{
+		Type:  ContentBlockTypeDocument,
+		Title: title,
+		Source: &ContentSource{
+			Type:      "text",
+			MediaType: "text/plain",
+			Data:      text,
+		},
+	}
+}
+
+// NewContentDocument returns a document made of blocks, typically text blocks
+// that each become a separately citable chunk.
+func NewContentDocument(title string, blocks ...ContentBlockParam) ContentBlockParam {
+	return ContentBlockParam{
+		Type:  ContentBlockTypeDocument,
+		Title: title,
+		Source: &ContentSource{
+			Type:    "content",
+			Content: blocks,
+		},
+	}
+}
+
+// WithCitations returns a copy of a document block with citations enabled, so
+// the response can cite passages from it.
+func (b ContentBlockParam) WithCitations() ContentBlockParam {
+	b.Citations = &CitationsConfig{Enabled: true}
+	return b
+}
+
+type messageParamJSON struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// Param returns b as a block of a request, e.g. to send a response back to the
+// model.
+func (b ContentBlock) Param() ContentBlockParam {
+	return ContentBlockParam{
+		Type:      b.Type,
+		Text:      b.Text,
+		Thinking:  b.Thinking,
+		Signature: b.Signature,
+		ID:        b.ID,
+		Name:      b.Name,
+		Input:     b.Input,
+		ToolUseID: b.ToolUseID,
+		Content:   b.Content,
+	}
+}
+
+// Param returns m as a message of a request, keeping all of its content blocks,
+// e.g. to add a response to the conversation history.
+func (m *Message) Param() MessageParam {
+	blocks := make([]ContentBlockParam, len(m.Content))
+	for i, block := range m.Content {
+		blocks[i] = block.Param()
+	}
+	return MessageParam{Role: m.Role, ContentBlocks: blocks}
+}
+
+// MarshalJSON sends Content as a plain string unless ContentBlocks are set, in
+// which case content is an array of blocks led by Content as a text block.
+func (m MessageParam) MarshalJSON() ([]byte, error) {
+	var content interface{} = m.Content
+	if len(m.ContentBlocks) > 0 {
+		blocks := m.ContentBlocks
+		if m.Content != "" {
+			blocks = append([]ContentBlockParam{NewTextBlock(m.Content)}, blocks...)
+		}
+		content = blocks
+	}
+
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(messageParamJSON{Role: m.Role, Content: raw})
+}
+
+func (m *MessageParam) UnmarshalJSON(data []byte) error {
+	var raw messageParamJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = MessageParam{Role: raw.Role}
+	if len(raw.Content) > 0 && raw.Content[0] == '[' {
+		return json.Unmarshal(raw.Content, &m.ContentBlocks)
+	}
+	if len(raw.Content) > 0 && string(raw.Content) != "null" {
+		return json.Unmarshal(raw.Content, &m.Content)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageParamJSON(t *testing.T) {
+	text := MessageParam{Role: RoleUser, Content: "Hello"}
+	b, err := json.Marshal(text)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":"Hello"}`, string(b))
+
+	var decoded MessageParam
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, text, decoded)
+
+	blocks := MessageParam{
+		Role:          RoleUser,
+		Content:       "Describe this",
+		ContentBlocks: []ContentBlockParam{NewImageBlock("image/jpeg", "/9j/4AAQ")},
+	}
+	b, err = json.Marshal(blocks)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":[
+		{"type":"text","text":"Describe this"},
+		{"type":"image","source":{"type":"base64","media_type":"image/jpeg","data":"/9j/4AAQ"}}
+	]}`, string(b))
+
+	decoded = MessageParam{}
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, MessageParam{
+		Role:          RoleUser,
+		ContentBlocks: []ContentBlockParam{NewTextBlock("Describe this"), NewImageBlock("image/jpeg", "/9j/4AAQ")},
+	}, decoded)
+}
+
+func TestDocumentBlocks(t *testing.T) {
+	b, err := json.Marshal(NewTextDocument("Handbook", "Employees accrue 20 days of leave."))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type":"document",
+		"title":"Handbook",
+		"source":{"type":"text","media_type":"text/plain","data":"Employees accrue 20 days of leave."}
+	}`, string(b))
+
+	b, err = json.Marshal(NewContentDocument("FAQ",
+		NewTextBlock("Leave accrues monthly."),
+		NewTextBlock("Unused leave carries over."),
+	).WithCitations())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type":"document",
+		"title":"FAQ",
+		"source":{"type":"content","content":[
+			{"type":"text","text":"Leave accrues monthly."},
+			{"type":"text","text":"Unused leave carries over."}
+		]},
+		"citations":{"enabled":true}
+	}`, string(b))
+
+	doc := NewTextDocument("Handbook", "text")
+	cited := doc.WithCitations()
+	assert.Nil(t, doc.Citations)
+	assert.True(t, cited.Citations.Enabled)
+
+	var decoded ContentBlockParam
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "FAQ", decoded.Title)
+	assert.Len(t, decoded.Source.Content, 2)
+
+	// text documents are accepted by models without PDF support
+	assert.NoError(t, MessageCreateParams{
+		Model:    ModelClaude3Haiku,
+		Messages: []MessageParam{{Role: RoleUser, ContentBlocks: []ContentBlockParam{doc}}},
+	}.Validate())
+}
+
+func TestContentBlockTypeWireValues(t *testing.T) {
+	assert.Equal(t, ContentBlockType("text"), ContentBlockTypeText)
+	assert.Equal(t, ContentBlockType("image"), ContentBlockTypeImage)
+	assert.Equal(t, ContentBlockType("document"), ContentBlockTypeDocument)
+	assert.Equal(t, ContentBlockType("tool_use"), ContentBlockTypeToolUse)
+	assert.Equal(t, ContentBlockType("tool_result"), ContentBlockTypeToolResult)
+	assert.Equal(t, ContentBlockType("thinking"), ContentBlockTypeThinking)
+
+	var block ContentBlock
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{}}`), &block))
+	assert.Equal(t, ContentBlockTypeToolUse, block.Type)
+
+	b, err := json.Marshal(NewTextBlock("Hi"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"text","text":"Hi"}`, string(b))
+}
@@ -0,0 +1,105 @@
+package anthropic
+
+import "context"
+
+// Conversation keeps the message history of a multi-turn exchange with a
+// model along with running usage statistics.
+type Conversation struct {
+	client    *Client
+	Model     string
+	System    string
+	MaxTokens int
+
+	messages []MessageParam
+
+	TotalInputTokens  int
+	TotalOutputTokens int
+	TotalCost         float64
+	TurnCount         int
+}
+
+type ConversationState struct {
+	System            string
+	Messages          []MessageParam
+	TotalInputTokens  int
+	TotalOutputTokens int
+	TotalCost         float64
+	TurnCount         int
+}
+
+func NewConversation(client *Client, model, system string, maxTokens int) *Conversation {
+	return &Conversation{
+		client:    client,
+		Model:     model,
+		System:    system,
+		MaxTokens: maxTokens,
+	}
+}
+
+// Send adds text as a user turn, requests the model's reply and records it in
+// the history. On error the history is left unchanged.
+func (c *Conversation) Send(ctx context.Context, text string) (*Message, error) {
+	messages := append(c.History(), MessageParam{Role: RoleUser, Content: text})
+
+	msg, err := c.client.CreateMessage(ctx, MessageCreateParams{
+		Model:     c.Model,
+		MaxTokens: c.MaxTokens,
+		System:    c.System,
+		Messages:  messages,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.messages = append(messages, MessageParam{Role: RoleAssistant, Content: msg.Text()})
+
+	c.TotalInputTokens += msg.Usage.InputTokens
+	c.TotalOutputTokens += msg.Usage.OutputTokens
+	c.TotalCost += estimateCost(c.Model, msg.Usage)
+	c.TurnCount++
+
+	return msg, nil
+}
+
+// Process sends input and returns the text of the reply, making a
+// Conversation usable as an Agent in a Pipeline.
+func (c *Conversation) Process(ctx context.Context, input string) (string, error) {
+	msg, err := c.Send(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return msg.Text(), nil
+}
+
+// History returns a copy of the messages exchanged so far.
+func (c *Conversation) History() []MessageParam {
+	return append([]MessageParam(nil), c.messages...)
+}
+
+// Reset clears the message history, keeping the system prompt and the
+// accumulated statistics.
+func (c *Conversation) Reset() {
+	c.messages = nil
+}
+
+func (c *Conversation) Snapshot() ConversationState {
+	return ConversationState{
+		System:            c.System,
+		Messages:          c.History(),
+		TotalInputTokens:  c.TotalInputTokens,
+		TotalOutputTokens: c.TotalOutputTokens,
+		TotalCost:         c.TotalCost,
+		TurnCount:         c.TurnCount,
+	}
+}
+
+// Restore returns the conversation to a state captured by Snapshot, allowing a
+// conversation to be branched from a checkpoint.
+func (c *Conversation) Restore(state ConversationState) {
+	c.System = state.System
+	c.messages = append([]MessageParam(nil), state.Messages...)
+	c.TotalInputTokens = state.TotalInputTokens
+	c.TotalOutputTokens = state.TotalOutputTokens
+	c.TotalCost = state.TotalCost
+	c.TurnCount = state.TurnCount
+}
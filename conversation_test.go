@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// echoHandler answers message requests by echoing the last message.
+func echoHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		last := params.Messages[len(params.Messages)-1]
+		fmt.Fprintf(w, `{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"echo: %s"}],"model":%q,"usage":{"input_tokens":1000,"output_tokens":200}}`, last.Content, params.Model)
+	}
+}
+
+func TestConversation(t *testing.T) {
+	conv := NewConversation(newTestClient(t, echoHandler(t)), ModelClaude3Haiku, "Be helpful", 100)
+
+	msg, err := conv.Send(context.Background(), "one")
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: one", msg.Content[0].Text)
+	_, err = conv.Send(context.Background(), "two")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []MessageParam{
+		{Role: RoleUser, Content: "one"},
+		{Role: RoleAssistant, Content: "echo: one"},
+		{Role: RoleUser, Content: "two"},
+		{Role: RoleAssistant, Content: "echo: two"},
+	}, conv.History())
+	assert.Equal(t, 2, conv.TurnCount)
+	assert.Equal(t, 2000, conv.TotalInputTokens)
+	assert.Equal(t, 400, conv.TotalOutputTokens)
+	assert.InDelta(t, 2*(1000*0.25+200*1.25)/1_000_000, conv.TotalCost, 1e-12)
+
+	conv.Reset()
+	assert.Empty(t, conv.History())
+	assert.Equal(t, "Be helpful", conv.System)
+	assert.Equal(t, 2, conv.TurnCount)
+}
+
+func TestConversationSnapshotRestore(t *testing.T) {
+	conv := NewConversation(newTestClient(t, echoHandler(t)), ModelClaude3Haiku, "Be helpful", 100)
+	_, err := conv.Send(context.Background(), "shared")
+	assert.NoError(t, err)
+
+	checkpoint := conv.Snapshot()
+
+	_, err = conv.Send(context.Background(), "branch a")
+	assert.NoError(t, err)
+	assert.Len(t, conv.History(), 4)
+
+	conv.Restore(checkpoint)
+	assert.Len(t, conv.History(), 2)
+	assert.Equal(t, 1, conv.TurnCount)
+
+	_, err = conv.Send(context.Background(), "branch b")
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: branch b", conv.History()[3].Content)
+
+	// the checkpoint is unaffected by sends after it was taken
+	assert.Len(t, checkpoint.Messages, 2)
+}
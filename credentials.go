@@ -0,0 +1,107 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// KeyProviderFunc adapts a function to a KeyProvider.
+type KeyProviderFunc func(ctx context.Context) (string, error)
+
+func (f KeyProviderFunc) GetAPIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type staticKeyProvider string
+
+func (k staticKeyProvider) GetAPIKey(context.Context) (string, error) {
+	return string(k), nil
+}
+
+// StaticKeyProvider returns a KeyProvider that always supplies key, for
+// callers that want a provider but have a fixed key.
+func StaticKeyProvider(key string) KeyProvider {
+	return staticKeyProvider(key)
+}
+
+// WithAPIKeyFunc is like WithAPIKeyProvider but takes a function, such as one
+// that reads the current key from Vault.
+func WithAPIKeyFunc(fn func(ctx context.Context) (string, error)) ClientOption {
+	return WithAPIKeyProvider(KeyProviderFunc(fn))
+}
+
+// WithAuthTokenFunc sets a function consulted for the bearer token on every
+// request, in place of WithAuthToken. An API key, if set, takes precedence.
+func WithAuthTokenFunc(fn func(ctx context.Context) (string, error)) ClientOption {
+	return func(c *Client) {
+		c.tokenProvider = fn
+	}
+}
+
+type credentialRefreshKey struct{}
+
+// CredentialRefresh reports whether a credential provider is being called
+// because the API rejected the credentials it last returned with a 401. A
+// provider that caches credentials should fetch fresh ones.
+func CredentialRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(credentialRefreshKey{}).(bool)
+	return refresh
+}
+
+// authenticate sets the credentials for req. Providers are consulted once per
+// call, and cfg records whether one was so that a rejected request can be
+// retried with fresh credentials.
+func (c *Client) authenticate(ctx context.Context, req *http.Request, cfg *requestConfig) error {
+	if cfg.admin && c.adminKey != "" {
+		req.Header.Set("X-API-Key", c.adminKey)
+		return nil
+	}
+
+	apiKey := c.apiKey
+	if c.keyProvider != nil {
+		cfg.credentialProvider = true
+		var err error
+		apiKey, err = c.keyProvider.GetAPIKey(ctx)
+		if err != nil {
+			return &CredentialError{Credential: "API key", Err: err}
+		}
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+		return nil
+	}
+
+	authToken := c.authToken
+	if c.tokenProvider != nil {
+		cfg.credentialProvider = true
+		var err error
+		authToken, err = c.tokenProvider(ctx)
+		if err != nil {
+			return &CredentialError{Credential: "auth token", Err: err}
+		}
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
+	}
+	return nil
+}
+
+// canRefreshCredentials reports whether req, rejected with resp, can be sent
+// again with credentials fetched afresh from a provider.
+func canRefreshCredentials(req *http.Request, resp *http.Response) bool {
+	cfg := requestConfigFrom(req.Context())
+	replayable := req.Body == nil || req.GetBody != nil
+	return resp.StatusCode == http.StatusUnauthorized && cfg != nil && cfg.credentialProvider && replayable
+}
+
+// refreshCredentials asks the providers for fresh credentials for req, marking
+// the context with CredentialRefresh, and signs req again.
+func (c *Client) refreshCredentials(req *http.Request) error {
+	ctx := context.WithValue(req.Context(), credentialRefreshKey{}, true)
+	if err := c.authenticate(ctx, req, requestConfigFrom(req.Context())); err != nil {
+		return err
+	}
+
+	return c.resign(req)
+}
@@ -0,0 +1,115 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// vaultProvider hands out a new key each time it is called, recording whether
+// each call was a forced refresh.
+type vaultProvider struct {
+	version   int
+	refreshes []bool
+	err       error
+}
+
+func (p *vaultProvider) get(ctx context.Context) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.version++
+	p.refreshes = append(p.refreshes, CredentialRefresh(ctx))
+	return fmt.Sprintf("key-%d", p.version), nil
+}
+
+func TestWithAPIKeyFuncRotation(t *testing.T) {
+	var gotKeys []string
+	statuses := []int{529, http.StatusOK, http.StatusOK, http.StatusOK}
+	provider := &vaultProvider{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotKeys = append(gotKeys, r.Header.Get("X-API-Key"))
+		status := statuses[0]
+		statuses = statuses[1:]
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}, WithAPIKeyFunc(provider.get))
+	client.minRetryDelay = time.Millisecond
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.CreateMessage(context.Background(), params)
+		assert.NoError(t, err)
+	}
+	// the retry of the first request reuses its key
+	assert.Equal(t, []string{"key-1", "key-1", "key-2", "key-3"}, gotKeys)
+	assert.Equal(t, []bool{false, false, false}, provider.refreshes)
+}
+
+func TestWithAPIKeyFuncRefreshOn401(t *testing.T) {
+	var gotKeys []string
+	provider := &vaultProvider{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		gotKeys = append(gotKeys, key)
+		if key == "key-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}, WithAPIKeyFunc(provider.get))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key-1", "key-2"}, gotKeys)
+	assert.Equal(t, []bool{false, true}, provider.refreshes)
+
+	// a static key that is rejected is not retried
+	gotKeys = nil
+	client = client.WithOptions(WithAPIKeyProvider(nil), WithAPIKey("key-1"))
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Equal(t, []string{"key-1"}, gotKeys)
+}
+
+func TestWithAuthTokenFunc(t *testing.T) {
+	var gotAuth []string
+	provider := &vaultProvider{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}))
+	defer server.Close()
+	// newClient leaves the API key from the environment unset
+	client := newClient(WithBaseURL(server.URL), WithAuthTokenFunc(provider.get))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bearer key-1"}, gotAuth)
+}
+
+func TestCredentialError(t *testing.T) {
+	vaultErr := errors.New("vault sealed")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request may be sent without credentials")
+	}))
+	defer server.Close()
+	client := newClient(WithBaseURL(server.URL), WithAuthTokenFunc((&vaultProvider{err: vaultErr}).get))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	var credErr *CredentialError
+	if assert.ErrorAs(t, err, &credErr) {
+		assert.Equal(t, "auth token", credErr.Credential)
+	}
+	assert.ErrorIs(t, err, vaultErr)
+	assert.EqualError(t, err, "anthropic: getting auth token: vault sealed")
+}
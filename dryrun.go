@@ -0,0 +1,38 @@
+package anthropic
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+)
+
+// ErrDryRun is returned by CreateMessage and StreamMessage in place of a
+// response when the client was created with WithDryRun.
+var ErrDryRun = errors.New("anthropic: dry run, request not sent")
+
+// WithDryRun makes CreateMessage and StreamMessage validate their parameters
+// and log the request they would send to logger, then return ErrDryRun
+// without calling the API. Only the method, URL and body are logged, never
+// credentials, and no key or token provider is called to build them.
+func WithDryRun(logger *log.Logger) ClientOption {
+	return func(c *Client) {
+		c.dryRun = logger
+	}
+}
+
+// logDryRun logs req, which is not sent, and returns ErrDryRun.
+func (c *Client) logDryRun(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		if body, err = io.ReadAll(r); err != nil {
+			return err
+		}
+	}
+	c.dryRun.Printf("anthropic: dry run: %s %s %s", req.Method, req.URL, body)
+	return ErrDryRun
+}
@@ -0,0 +1,59 @@
+package anthropic
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDryRun(t *testing.T) {
+	var logs strings.Builder
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("dry run must not send requests")
+	}, WithDryRun(log.New(&logs, "", 0)), WithAPIKey("sk-secret"))
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+
+	msg, err := client.CreateMessage(context.Background(), params)
+	assert.Nil(t, msg)
+	assert.ErrorIs(t, err, ErrDryRun)
+
+	stream, err := client.StreamMessage(context.Background(), params)
+	assert.Nil(t, stream)
+	assert.ErrorIs(t, err, ErrDryRun)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], "anthropic: dry run: POST "+client.baseURL+"/v1/messages ")
+		assert.Contains(t, lines[0], `"messages":[{"role":"user","content":"Hi"}]`)
+		assert.NotContains(t, lines[0], `"stream"`)
+		assert.Contains(t, lines[1], `"stream":true`)
+	}
+	assert.NotContains(t, logs.String(), "sk-secret")
+
+	// invalid params are still rejected
+	logs.Reset()
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude21, MaxTokens: 10, Messages: []MessageParam{
+		{Role: RoleUser, ContentBlocks: []ContentBlockParam{NewImageBlock("image/png", "iVBORw0KGgo=")}},
+	}})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrDryRun)
+	assert.Empty(t, logs.String())
+
+	// credential providers are never consulted
+	logs.Reset()
+	client = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("dry run must not send requests")
+	}, WithDryRun(log.New(&logs, "", 0)), WithAPIKeyFunc(func(context.Context) (string, error) {
+		t.Error("dry run must not fetch credentials")
+		return "sk-secret", nil
+	}))
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.ErrorIs(t, err, ErrDryRun)
+	_, err = client.StreamMessage(context.Background(), params)
+	assert.ErrorIs(t, err, ErrDryRun)
+	assert.NotEmpty(t, logs.String())
+}
@@ -0,0 +1,146 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type ErrorResponse struct {
+	Type  string      `json:"type"`
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// APIError is returned for any non-2xx response, and for a 2xx response whose
+// body isn't the JSON expected, such as a proxy's sign-in page, or that
+// streams an error event. Status specific errors such as NotFoundError wrap it, so errors.As(err, &apiErr)
+// matches all of them.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Type       string
+	Message    string
+	RequestID  string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	if e.Status == "" {
+		// from an error event in a stream that began with a success status
+		return fmt.Sprintf("anthropic: %s: %s", e.Type, e.Message)
+	}
+	if e.Type != "" || e.Message != "" {
+		return fmt.Sprintf("anthropic: %s - %s: %s", e.Status, e.Type, e.Message)
+	}
+	// bodies that aren't the API's JSON, such as a proxy's HTML error page,
+	// are summarized; Body keeps all of it
+	return fmt.Sprintf("anthropic: %s - %s", e.Status, bodySnippet(e.Body))
+}
+
+// maxBodySnippet is how much of an unrecognized response body errors quote.
+const maxBodySnippet = 200
+
+// bodySnippet collapses the whitespace in body and truncates it to
+// maxBodySnippet runes.
+func bodySnippet(body string) string {
+	snippet := strings.Join(strings.Fields(body), " ")
+	if runes := []rune(snippet); len(runes) > maxBodySnippet {
+		snippet = string(runes[:maxBodySnippet]) + "..."
+	}
+	return snippet
+}
+
+// PermissionError is returned when the credentials are valid but not allowed
+// to perform the request, such as calling the Admin API without an admin key.
+type PermissionError struct {
+	*APIError
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.APIError
+}
+
+type NotFoundError struct {
+	*APIError
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.APIError
+}
+
+// ConflictError is returned when a request conflicts with the current state of
+// the resource, such as canceling a batch that has already ended.
+type ConflictError struct {
+	*APIError
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.APIError
+}
+
+// CredentialError is returned when a credential provider, such as one set with
+// WithAPIKeyFunc, fails. The request is not sent.
+type CredentialError struct {
+	// Credential names what was requested, "API key" or "auth token".
+	Credential string
+	Err        error
+}
+
+func (e *CredentialError) Error() string {
+	return fmt.Sprintf("anthropic: getting %s: %v", e.Credential, e.Err)
+}
+
+func (e *CredentialError) Unwrap() error {
+	return e.Err
+}
+
+// newStreamError maps the payload of a stream's error event to an *APIError,
+// so it can be matched like the error of a failed response. StatusCode is
+// zero, as the response itself succeeded.
+func newStreamError(data []byte) error {
+	var errResp ErrorResponse
+	if err := json.Unmarshal(data, &errResp); err != nil || errResp.Error.Type == "" {
+		return fmt.Errorf("stream error: %s", data)
+	}
+	return &APIError{
+		Type:    errResp.Error.Type,
+		Message: errResp.Error.Message,
+		Body:    string(data),
+	}
+}
+
+// newAPIError consumes the body of a failed response and maps it to a typed
+// error.
+func newAPIError(resp *http.Response) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  resp.Header.Get("request-id"),
+		Body:       string(bodyBytes),
+	}
+
+	// other bodies, such as a gateway's HTML error page, are only kept as Body
+	var errResp ErrorResponse
+	if err := json.Unmarshal(bodyBytes, &errResp); err == nil {
+		apiErr.Type = errResp.Error.Type
+		apiErr.Message = errResp.Error.Message
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return &PermissionError{apiErr}
+	case http.StatusNotFound:
+		return &NotFoundError{apiErr}
+	case http.StatusConflict:
+		return &ConflictError{apiErr}
+	}
+	return apiErr
+}
@@ -0,0 +1,39 @@
+package anthropic
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WithModelFallback sets an ordered chain of models that CreateMessage falls
+// back to when the requested model is overloaded or not found. A request for
+// a model in the chain continues with the models after it; a request for any
+// other model tries the whole chain. No model is tried twice in one call. The
+// model that served the request is reported in Message.Model.
+func WithModelFallback(models ...string) ClientOption {
+	return func(c *Client) {
+		c.fallbackModels = models
+	}
+}
+
+// fallbacksFor returns the models to try, in order, if model fails.
+func (c *Client) fallbacksFor(model string) []string {
+	for i, m := range c.fallbackModels {
+		if m == model {
+			return c.fallbackModels[i+1:]
+		}
+	}
+	return c.fallbackModels
+}
+
+// shouldFallback reports whether err means the model can't serve the request
+// right now, so another model might.
+func shouldFallback(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == 529 ||
+		apiErr.Type == "overloaded_error" ||
+		apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,124 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fallbackHandler answers message requests with the status statuses gives for
+// their model, success by default, recording the models requested.
+func fallbackHandler(t *testing.T, statuses map[string]int, requested *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		*requested = append(*requested, params.Model)
+
+		switch statuses[params.Model] {
+		case 529:
+			w.WriteHeader(529)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+		case http.StatusNotFound:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"type":"error","error":{"type":"not_found_error","message":"model: %s"}}`, params.Model)
+		case http.StatusBadRequest:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad request"}}`)
+		default:
+			fmt.Fprintf(w, `{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Ok"}],"model":%q}`, params.Model)
+		}
+	}
+}
+
+func TestModelFallback(t *testing.T) {
+	var requested []string
+	client := newTestClient(t, fallbackHandler(t, map[string]int{ModelClaude35Sonnet: 529}, &requested), WithMaxRetries(0),
+		WithModelFallback(ModelClaude35Sonnet, ModelClaude3Haiku))
+
+	msg, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, ModelClaude3Haiku, msg.Model)
+	assert.Equal(t, []string{ModelClaude35Sonnet, ModelClaude3Haiku}, requested)
+}
+
+func TestModelFallbackChain(t *testing.T) {
+	var requested []string
+	client := newTestClient(t, fallbackHandler(t, map[string]int{"claude-next": http.StatusNotFound, ModelClaude35Sonnet: 529}, &requested), WithMaxRetries(0),
+		WithModelFallback(ModelClaude35Sonnet, ModelClaude3Haiku))
+
+	// a model outside the chain falls back through all of it
+	msg, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: "claude-next", MaxTokens: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, ModelClaude3Haiku, msg.Model)
+	assert.Equal(t, []string{"claude-next", ModelClaude35Sonnet, ModelClaude3Haiku}, requested)
+}
+
+func TestModelFallbackExhausted(t *testing.T) {
+	var requested []string
+	client := newTestClient(t, fallbackHandler(t, map[string]int{ModelClaude35Sonnet: 529, ModelClaude3Haiku: 529}, &requested), WithMaxRetries(0),
+		WithModelFallback(ModelClaude35Sonnet, ModelClaude3Haiku))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 10})
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "overloaded_error", apiErr.Type)
+	}
+	assert.Equal(t, []string{ModelClaude35Sonnet, ModelClaude3Haiku}, requested)
+}
+
+func TestModelFallbackOtherErrors(t *testing.T) {
+	var requested []string
+	client := newTestClient(t, fallbackHandler(t, map[string]int{ModelClaude35Sonnet: http.StatusBadRequest}, &requested), WithMaxRetries(0),
+		WithModelFallback(ModelClaude35Sonnet, ModelClaude3Haiku))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 10})
+	assert.Error(t, err)
+	assert.Equal(t, []string{ModelClaude35Sonnet}, requested)
+
+	// without a chain nothing falls back
+	requested = nil
+	client = newTestClient(t, fallbackHandler(t, map[string]int{ModelClaude35Sonnet: 529}, &requested), WithMaxRetries(0))
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 10})
+	assert.Error(t, err)
+	assert.Equal(t, []string{ModelClaude35Sonnet}, requested)
+}
+
+func TestModelFallbackResponseMeta(t *testing.T) {
+	var requested []string
+	client := newTestClient(t, fallbackHandler(t, map[string]int{ModelClaude3Opus: 529}, &requested), WithMaxRetries(0),
+		WithModelFallback(ModelClaude3Opus, ModelClaude35Sonnet))
+
+	var meta ResponseMeta
+	msg, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Opus, MaxTokens: 10}, WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, ModelClaude35Sonnet, msg.Model)
+	assert.True(t, meta.FallbackOccurred)
+	assert.Equal(t, ModelClaude3Opus, meta.RequestedModel)
+	assert.Equal(t, http.StatusOK, meta.StatusCode)
+
+	// reusing meta for a call served by the requested model clears the flag
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 10}, WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.False(t, meta.FallbackOccurred)
+	assert.Equal(t, ModelClaude35Sonnet, meta.RequestedModel)
+}
+
+func TestModelFallbackOnce(t *testing.T) {
+	var requested []string
+	client := newTestClient(t, fallbackHandler(t, map[string]int{ModelClaude3Opus: 529, ModelClaude35Sonnet: 529, ModelClaude3Haiku: 529}, &requested), WithMaxRetries(0),
+		WithModelFallback(ModelClaude3Opus, ModelClaude35Sonnet, ModelClaude3Opus, ModelClaude3Haiku, ModelClaude35Sonnet))
+
+	// the chain repeats models, but each is tried only once
+	var meta ResponseMeta
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Opus, MaxTokens: 10}, WithResponseMeta(&meta))
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, 529, apiErr.StatusCode)
+	}
+	assert.Equal(t, []string{ModelClaude3Opus, ModelClaude35Sonnet, ModelClaude3Haiku}, requested)
+	assert.True(t, meta.FallbackOccurred)
+}
@@ -0,0 +1,155 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// filesOptions prepends the Files API beta flag to opts.
+func filesOptions(opts []RequestOption) []RequestOption {
+	return prependOptions(WithBetas(BetaFilesAPI), opts)
+}
+
+// File is an uploaded file that can be referenced by ID in later requests.
+type File struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	Filename     string    `json:"filename"`
+	SizeBytes    int64     `json:"size_bytes"`
+	MimeType     string    `json:"mime_type"`
+	CreatedAt    time.Time `json:"created_at"`
+	Downloadable bool      `json:"downloadable"`
+}
+
+// UploadFile uploads the contents of r as a file named name. The contents are
+// streamed to the API as they are read, so uploads are not retried.
+func (c *Client) UploadFile(ctx context.Context, name string, r io.Reader, mimeType string, opts ...RequestOption) (*File, error) {
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/files", nil,
+		streamBody{Reader: pr, contentType: form.FormDataContentType()},
+		filesOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		pw.CloseWithError(writeFileForm(form, name, r, mimeType))
+	}()
+
+	var file File
+	_, err = c.do(req, &file)
+	// unblocks the writer if the request ended before consuming the body
+	pr.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeFileForm(form *multipart.Writer, name string, r io.Reader, mimeType string) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(name)))
+	header.Set("Content-Type", mimeType)
+
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return err
+	}
+	return form.Close()
+}
+
+func (c *Client) ListFiles(ctx context.Context, params ListParams, opts ...RequestOption) (*Page[File], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/files", params.values(), nil, filesOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[File]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListFilesAutoPaging(ctx context.Context, params ListParams, opts ...RequestOption) *PageIterator[File] {
+	return newListIterator(ctx, params, func(ctx context.Context, params ListParams) (*Page[File], error) {
+		return c.ListFiles(ctx, params, opts...)
+	})
+}
+
+// GetFile retrieves a file's metadata. A missing file returns a
+// *NotFoundError.
+func (c *Client) GetFile(ctx context.Context, id string, opts ...RequestOption) (*File, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/files/"+url.PathEscape(id), nil, nil, filesOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	_, err = c.do(req, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// DownloadFileContent streams the contents of a file, such as one created by
+// the code execution tool. The caller must close the returned body. The
+// content's type and length are available through WithResponseMeta.
+func (c *Client) DownloadFileContent(ctx context.Context, id string, opts ...RequestOption) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/files/"+url.PathEscape(id)+"/content", nil, nil, filesOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+type DeletedFile struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+func (c *Client) DeleteFile(ctx context.Context, id string, opts ...RequestOption) (*DeletedFile, error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/v1/files/"+url.PathEscape(id), nil, nil, filesOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted DeletedFile
+	_, err = c.do(req, &deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleted, nil
+}
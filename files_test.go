@@ -0,0 +1,236 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFileJSON = `{"id":"file_011CNha8iCJcU1wXNR6q4V8w","type":"file","filename":"report.pdf","size_bytes":1024,"mime_type":"application/pdf","created_at":"2025-01-01T00:00:00Z","downloadable":false}`
+
+func TestUploadFile(t *testing.T) {
+	content := "%PDF-1.4 quarterly report"
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/files", r.URL.Path)
+		assert.Equal(t, string(BetaFilesAPI), r.Header.Get("anthropic-beta"))
+		assert.Equal(t, "test", r.Header.Get("X-API-Key"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data; boundary="))
+
+		reader, err := r.MultipartReader()
+		if !assert.NoError(t, err) {
+			return
+		}
+		part, err := reader.NextPart()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "file", part.FormName())
+		assert.Equal(t, `quarterly "final".pdf`, part.FileName())
+		assert.Equal(t, "application/pdf", part.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(part)
+		assert.Equal(t, content, string(body))
+
+		_, err = reader.NextPart()
+		assert.ErrorIs(t, err, io.EOF)
+
+		io.WriteString(w, testFileJSON)
+	})
+
+	file, err := client.UploadFile(context.Background(), `quarterly "final".pdf`, strings.NewReader(content), "application/pdf")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "file_011CNha8iCJcU1wXNR6q4V8w", file.ID)
+	assert.Equal(t, "file", file.Type)
+	assert.Equal(t, "report.pdf", file.Filename)
+	assert.Equal(t, int64(1024), file.SizeBytes)
+	assert.Equal(t, "application/pdf", file.MimeType)
+	assert.Equal(t, 2025, file.CreatedAt.Year())
+}
+
+func TestUploadFileError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		io.WriteString(w, `{"type":"error","error":{"type":"request_too_large","message":"File too large"}}`)
+	})
+
+	_, err := client.UploadFile(context.Background(), "big.bin", bytes.NewReader(make([]byte, 1024)), "application/octet-stream")
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "request_too_large", apiErr.Type)
+}
+
+type repeatReader byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestUploadFileStreams(t *testing.T) {
+	const size = 10 << 20
+
+	var received int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if !assert.NoError(t, err) {
+			return
+		}
+		part, err := reader.NextPart()
+		if !assert.NoError(t, err) {
+			return
+		}
+		received, _ = io.Copy(io.Discard, part)
+		io.WriteString(w, testFileJSON)
+	})
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	_, err := client.UploadFile(context.Background(), "large.bin", io.LimitReader(repeatReader('a'), size), "application/octet-stream")
+	assert.NoError(t, err)
+
+	runtime.ReadMemStats(&after)
+	assert.Equal(t, int64(size), received)
+	// the client and the test server together allocate far less than the upload
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(size/4))
+}
+
+func fileJSON(id string) string {
+	return strings.Replace(testFileJSON, "file_011CNha8iCJcU1wXNR6q4V8w", id, 1)
+}
+
+func TestListFiles(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/files", r.URL.Path)
+		assert.Equal(t, string(BetaFilesAPI), r.Header.Get("anthropic-beta"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		io.WriteString(w, pageJSON(t, []string{fileJSON("file_a"), fileJSON("file_b")}, true, "file_a", "file_b"))
+	})
+
+	page, err := client.ListFiles(context.Background(), ListParams{Limit: 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, "file_a", page.Data[0].ID)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "file_b", page.LastID)
+}
+
+func TestListFilesAutoPaging(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, string(BetaFilesAPI), r.Header.Get("anthropic-beta"))
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			io.WriteString(w, pageJSON(t, []string{fileJSON("file_a"), fileJSON("file_b")}, true, "file_a", "file_b"))
+		case "file_b":
+			io.WriteString(w, pageJSON(t, []string{fileJSON("file_c")}, false, "file_c", "file_c"))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("after_id"))
+		}
+	})
+
+	var ids []string
+	it := client.ListFilesAutoPaging(context.Background(), ListParams{Limit: 2})
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"file_a", "file_b", "file_c"}, ids)
+}
+
+func TestGetFile(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, string(BetaFilesAPI), r.Header.Get("anthropic-beta"))
+		if r.URL.Path == "/v1/files/file_missing" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"File not found"}}`)
+			return
+		}
+		assert.Equal(t, "/v1/files/file_011CNha8iCJcU1wXNR6q4V8w", r.URL.Path)
+		io.WriteString(w, testFileJSON)
+	})
+
+	file, err := client.GetFile(context.Background(), "file_011CNha8iCJcU1wXNR6q4V8w")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "report.pdf", file.Filename)
+	}
+
+	_, err = client.GetFile(context.Background(), "file_missing")
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestDownloadFileContent(t *testing.T) {
+	content := "x,y\n1,2\n"
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, string(BetaFilesAPI), r.Header.Get("anthropic-beta"))
+		if r.URL.Path == "/v1/files/file_missing/content" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"File not found"}}`)
+			return
+		}
+		assert.Equal(t, "/v1/files/file_01/content", r.URL.Path)
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Header().Set("request-id", "req_01")
+		io.WriteString(w, content)
+	})
+
+	var meta ResponseMeta
+	body, err := client.DownloadFileContent(context.Background(), "file_01", WithResponseMeta(&meta))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer body.Close()
+	got, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(got))
+	assert.Equal(t, "text/csv", meta.ContentType)
+	assert.Equal(t, int64(len(content)), meta.ContentLength)
+	assert.Equal(t, "req_01", meta.RequestID)
+	assert.Equal(t, http.StatusOK, meta.StatusCode)
+
+	_, err = client.DownloadFileContent(context.Background(), "file_missing", WithResponseMeta(&meta))
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, http.StatusNotFound, meta.StatusCode)
+}
+
+func TestDeleteFile(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, string(BetaFilesAPI), r.Header.Get("anthropic-beta"))
+		if r.URL.Path == "/v1/files/file_missing" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"File not found"}}`)
+			return
+		}
+		assert.Equal(t, "/v1/files/file_01", r.URL.Path)
+		io.WriteString(w, `{"id":"file_01","type":"file_deleted"}`)
+	})
+
+	deleted, err := client.DeleteFile(context.Background(), "file_01")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "file_01", deleted.ID)
+		assert.Equal(t, "file_deleted", deleted.Type)
+	}
+
+	_, err = client.DeleteFile(context.Background(), "file_missing")
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
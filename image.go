@@ -0,0 +1,121 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+// ImageLimits bounds the images accepted by ValidateImageLimits. A zero field
+// is not checked.
+type ImageLimits struct {
+	// MaxBytes is the largest accepted size of the encoded image.
+	MaxBytes int
+	// MaxDimension is the largest accepted width or height in pixels.
+	MaxDimension int
+	// MaxMegapixels is the largest accepted width times height, in millions
+	// of pixels.
+	MaxMegapixels float64
+}
+
+// DefaultImageLimits are the limits the API enforces on each image.
+var DefaultImageLimits = ImageLimits{
+	MaxBytes:     5 * 1024 * 1024,
+	MaxDimension: 8000,
+}
+
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ValidateImage sniffs the format of data and checks it against
+// DefaultImageLimits, returning the media type to send it with.
+func ValidateImage(data []byte) (mediaType string, err error) {
+	return ValidateImageLimits(data, DefaultImageLimits)
+}
+
+// ValidateImageLimits is like ValidateImage but checks data against limits.
+func ValidateImageLimits(data []byte, limits ImageLimits) (mediaType string, err error) {
+	mediaType = http.DetectContentType(data)
+	if !supportedImageTypes[mediaType] {
+		return "", fmt.Errorf("anthropic: unsupported image type %s, must be PNG, JPEG, GIF or WebP", mediaType)
+	}
+	if limits.MaxBytes > 0 && len(data) > limits.MaxBytes {
+		return "", fmt.Errorf("anthropic: image is %d bytes, exceeding the limit of %d", len(data), limits.MaxBytes)
+	}
+	if limits.MaxDimension <= 0 && limits.MaxMegapixels <= 0 {
+		return mediaType, nil
+	}
+
+	width, height, err := imageSize(mediaType, data)
+	if err != nil {
+		return "", fmt.Errorf("anthropic: reading %s image: %w", mediaType, err)
+	}
+	if limits.MaxDimension > 0 && (width > limits.MaxDimension || height > limits.MaxDimension) {
+		return "", fmt.Errorf("anthropic: image is %dx%d pixels, exceeding the limit of %d pixels per side", width, height, limits.MaxDimension)
+	}
+	if megapixels := float64(width) * float64(height) / 1e6; limits.MaxMegapixels > 0 && megapixels > limits.MaxMegapixels {
+		return "", fmt.Errorf("anthropic: image is %.2f megapixels, exceeding the limit of %.2f", megapixels, limits.MaxMegapixels)
+	}
+	return mediaType, nil
+}
+
+// imageSize returns the dimensions of an image without decoding its pixels.
+func imageSize(mediaType string, data []byte) (width, height int, err error) {
+	if mediaType == "image/webp" {
+		return webpSize(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// webpSize reads the dimensions from the header of a WebP image, which the
+// standard library can't decode.
+func webpSize(data []byte) (width, height int, err error) {
+	// RIFF header, then the first chunk's four-character code and size
+	if len(data) < 30 {
+		return 0, 0, fmt.Errorf("truncated WebP header")
+	}
+	chunk := data[12:16]
+	switch string(chunk) {
+	case "VP8 ":
+		// lossy: 14-bit dimensions after the frame tag and start code
+		width = int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+	case "VP8L":
+		// lossless: 14-bit dimensions, less one, after the signature byte
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		width = int(bits&0x3fff) + 1
+		height = int(bits>>14&0x3fff) + 1
+	case "VP8X":
+		// extended: 24-bit canvas dimensions, less one, after the flags
+		width = int(uint32(data[24])|uint32(data[25])<<8|uint32(data[26])<<16) + 1
+		height = int(uint32(data[27])|uint32(data[28])<<8|uint32(data[29])<<16) + 1
+	default:
+		return 0, 0, fmt.Errorf("unknown WebP chunk %q", chunk)
+	}
+	return width, height, nil
+}
+
+// NewImageBlockFromBytes validates raw image data with ValidateImage and
+// returns an image block holding it, so an unsupported or oversized image is
+// rejected before it is sent.
+func NewImageBlockFromBytes(data []byte) (ContentBlockParam, error) {
+	mediaType, err := ValidateImage(data)
+	if err != nil {
+		return ContentBlockParam{}, err
+	}
+	return NewImageBlock(mediaType, base64.StdEncoding.EncodeToString(data)), nil
+}
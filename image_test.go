@@ -0,0 +1,57 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestValidateImage(t *testing.T) {
+	data := encodePNG(t, 64, 48)
+	mediaType, err := ValidateImage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", mediaType)
+
+	block, err := NewImageBlockFromBytes(data)
+	assert.NoError(t, err)
+	assert.Equal(t, NewImageBlock("image/png", base64.StdEncoding.EncodeToString(data)), block)
+
+	// a VP8X header for a 1024x768 canvas
+	webp := append([]byte("RIFF\x16\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x00\x00\x00\x00"), 0xff, 0x03, 0x00, 0xff, 0x02, 0x00)
+	mediaType, err = ValidateImageLimits(webp, ImageLimits{MaxDimension: 1024, MaxMegapixels: 0.8})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/webp", mediaType)
+	_, err = ValidateImageLimits(webp, ImageLimits{MaxDimension: 1000})
+	assert.EqualError(t, err, "anthropic: image is 1024x768 pixels, exceeding the limit of 1000 pixels per side")
+}
+
+func TestValidateImageUnsupported(t *testing.T) {
+	_, err := ValidateImage([]byte("BM\x3a\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00"))
+	assert.EqualError(t, err, "anthropic: unsupported image type image/bmp, must be PNG, JPEG, GIF or WebP")
+
+	_, err = NewImageBlockFromBytes([]byte("%PDF-1.7"))
+	assert.EqualError(t, err, "anthropic: unsupported image type application/pdf, must be PNG, JPEG, GIF or WebP")
+}
+
+func TestValidateImageOversized(t *testing.T) {
+	_, err := ValidateImage(encodePNG(t, 8001, 1))
+	assert.EqualError(t, err, "anthropic: image is 8001x1 pixels, exceeding the limit of 8000 pixels per side")
+
+	data := encodePNG(t, 2000, 1000)
+	_, err = ValidateImageLimits(data, ImageLimits{MaxMegapixels: 1.15})
+	assert.EqualError(t, err, "anthropic: image is 2.00 megapixels, exceeding the limit of 1.15")
+
+	_, err = ValidateImageLimits(data, ImageLimits{MaxBytes: 100})
+	assert.ErrorContains(t, err, "exceeding the limit of 100")
+}
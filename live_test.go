@@ -0,0 +1,72 @@
+package anthropic_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	anthropic "github.com/gage-technologies/anthropic-go"
+	"github.com/gage-technologies/anthropic-go/replay"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingsDir holds the API exchanges the live tests record and replay.
+var recordingsDir = filepath.Join("testdata", "recordings")
+
+// newLiveClient returns a client for a test that needs the real API. With
+// ANTHROPIC_RECORD=1 its requests go to the API and are saved to
+// testdata/recordings; with ANTHROPIC_REPLAY=1 they are answered from those
+// recordings without network access. Otherwise the test is skipped.
+func newLiveClient(t *testing.T) *anthropic.Client {
+	t.Helper()
+	switch {
+	case os.Getenv("ANTHROPIC_RECORD") == "1":
+		return replay.NewRecorder(anthropic.NewClient(), recordingsDir).Client
+	case os.Getenv("ANTHROPIC_REPLAY") == "1":
+		if _, err := os.Stat(recordingsDir); err != nil {
+			t.Skipf("no recordings in %s; record them with ANTHROPIC_RECORD=1", recordingsDir)
+		}
+		return replay.NewReplayer(recordingsDir).Client
+	}
+	t.Skip("needs the API; set ANTHROPIC_RECORD=1 to call it or ANTHROPIC_REPLAY=1 to replay recordings")
+	return nil
+}
+
+func TestMessages(t *testing.T) {
+	client := newLiveClient(t)
+	res, err := client.CreateMessage(context.Background(), anthropic.MessageCreateParams{
+		Model:     anthropic.ModelClaude3Sonnet,
+		MaxTokens: 4000,
+		System:    "You are in test mode. You're job is to reply Ok to the user message. Only return 'Ok'",
+		Messages: []anthropic.MessageParam{
+			{Role: anthropic.RoleUser, Content: "Reply to this with only 'Ok'"},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Ok", res.Text())
+}
+
+func TestMessagesStream(t *testing.T) {
+	client := newLiveClient(t)
+	res, err := client.StreamMessage(context.Background(), anthropic.MessageCreateParams{
+		Model:     anthropic.ModelClaude3Sonnet,
+		MaxTokens: 4000,
+		System:    "You are in test mode. You're job is to reply Ok to the user message. Only return 'Ok'",
+		Messages: []anthropic.MessageParam{
+			{Role: anthropic.RoleUser, Content: "Reply to this with only 'Ok'"},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer res.Close()
+
+	for res.Next() {
+		// only the answer text is checked
+	}
+	assert.NoError(t, res.Err())
+	assert.Equal(t, "Ok", res.Text())
+}
@@ -1,13 +1,15 @@
 package anthropic
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 type StreamEvent string
@@ -23,6 +25,46 @@ const (
 	StreamEventContentBlockDelta StreamEvent = "content_block_delta"
 )
 
+func AllStreamEvents() []StreamEvent {
+	return []StreamEvent{
+		StreamEventPing,
+		StreamEventError,
+		StreamEventMessageStart,
+		StreamEventMessageStop,
+		StreamEventMessageDelta,
+		StreamEventContentBlockStart,
+		StreamEventContentBlockStop,
+		StreamEventContentBlockDelta,
+	}
+}
+
+// ParseStreamEvent maps s, ignoring case and surrounding whitespace, to one
+// of the known stream events.
+func ParseStreamEvent(s string) (StreamEvent, error) {
+	e := StreamEvent(strings.ToLower(strings.TrimSpace(s)))
+	if !e.IsValid() {
+		return "", fmt.Errorf("anthropic: unknown stream event %q", s)
+	}
+	return e, nil
+}
+
+func (e StreamEvent) IsValid() bool {
+	for _, known := range AllStreamEvents() {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (e StreamEvent) String() string {
+	return strings.ToLower(string(e))
+}
+
+// MessageStreamEvent is one event of a message stream. Which fields are set
+// depends on Type: ContentBlock is the new block on content_block_start and
+// the delta on content_block_delta, and is nil on content_block_stop, which
+// carries only Index.
 type MessageStreamEvent struct {
 	Type         StreamEvent   `json:"type"`
 	Message      *Message      `json:"message,omitempty"`
@@ -37,21 +79,82 @@ type Message struct {
 	Role         string         `json:"role"`
 	Content      []ContentBlock `json:"content"`
 	Model        string         `json:"model"`
-	StopReason   string         `json:"stop_reason"`
+	StopReason   StopReason     `json:"stop_reason"`
 	StopSequence string         `json:"stop_sequence"`
 	Usage        Usage          `json:"usage"`
 }
 
 type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type ContentBlockType `json:"type"`
+	Text string           `json:"text"`
+
+	// Set on thinking blocks.
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	// Set on input_json_delta stream events; see ToolInputAccumulator.
+	PartialJSON string `json:"partial_json,omitempty"`
+
+	// Set on tool_use and server_tool_use blocks.
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// Set on the results of server tools, such as web_search_tool_result.
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
 }
 
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// applyDelta folds in the usage reported by a message_delta. Its counts are
+// the message's totals so far, not increments, so each nonzero one replaces
+// the count rather than adding to it.
+func (u *Usage) applyDelta(delta Usage) {
+	if delta.InputTokens != 0 {
+		u.InputTokens = delta.InputTokens
+	}
+	if delta.OutputTokens != 0 {
+		u.OutputTokens = delta.OutputTokens
+	}
+	if delta.CacheCreationInputTokens != 0 {
+		u.CacheCreationInputTokens = delta.CacheCreationInputTokens
+	}
+	if delta.CacheReadInputTokens != 0 {
+		u.CacheReadInputTokens = delta.CacheReadInputTokens
+	}
+}
+
+// add sums the usage of another message, such as the continuation of a paused
+// turn, into u.
+func (u *Usage) add(delta Usage) {
+	u.InputTokens += delta.InputTokens
+	u.OutputTokens += delta.OutputTokens
+	u.CacheCreationInputTokens += delta.CacheCreationInputTokens
+	u.CacheReadInputTokens += delta.CacheReadInputTokens
 }
 
+// StopReason says why the model stopped generating a message.
+type StopReason string
+
+const (
+	StopReasonEndTurn      StopReason = "end_turn"
+	StopReasonMaxTokens    StopReason = "max_tokens"
+	StopReasonStopSequence StopReason = "stop_sequence"
+	StopReasonToolUse      StopReason = "tool_use"
+	// StopReasonRefusal means the model declined to answer, e.g. for safety
+	// reasons; the content received so far should not be shown as an answer.
+	StopReasonRefusal StopReason = "refusal"
+	// StopReasonPauseTurn means a long running server tool turn was paused;
+	// see ContinuePausedTurn.
+	StopReasonPauseTurn StopReason = "pause_turn"
+)
+
 type MessageDeltaWrapper struct {
 	Type  string       `json:"type"`
 	Delta MessageDelta `json:"delta"`
@@ -59,8 +162,16 @@ type MessageDeltaWrapper struct {
 }
 
 type MessageDelta struct {
-	StopReason   string  `json:"stop_reason"`
-	StopSequence *string `json:"stop_sequence"`
+	StopReason   StopReason `json:"stop_reason"`
+	StopSequence *string    `json:"stop_sequence"`
+}
+
+// ContentBlockEvent is the payload of content_block_start and
+// content_block_stop events. ContentBlock is nil for content_block_stop.
+type ContentBlockEvent struct {
+	Type         string        `json:"type"`
+	Index        int           `json:"index"`
+	ContentBlock *ContentBlock `json:"content_block,omitempty"`
 }
 
 type ContentBlockDelta struct {
@@ -69,9 +180,15 @@ type ContentBlockDelta struct {
 	Delta TextDelta `json:"delta"`
 }
 
+// TextDelta is the delta of a content_block_delta event. Its Type says which
+// of its fields is set: text_delta sets Text, thinking_delta Thinking,
+// signature_delta Signature and input_json_delta PartialJSON.
 type TextDelta struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	Thinking    string `json:"thinking,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
 }
 
 type MessageCreateParams struct {
@@ -83,17 +200,207 @@ type MessageCreateParams struct {
 	Stream        bool              `json:"stream,omitempty"`
 	System        string            `json:"system,omitempty"`
 	Temperature   float64           `json:"temperature,omitempty"`
+	Thinking      *Thinking         `json:"thinking,omitempty"`
+	ToolChoice    *ToolChoice       `json:"tool_choice,omitempty"`
+	Tools         []Tool            `json:"tools,omitempty"`
+	MCPServers    []MCPServer       `json:"mcp_servers,omitempty"`
 	TopK          int               `json:"top_k,omitempty"`
 	TopP          float64           `json:"top_p,omitempty"`
 }
 
 type MessageParam struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role          string
+	Content       string
+	ContentBlocks []ContentBlockParam
+}
+
+// MaxStopSequences is the most stop sequences a message request may have.
+const MaxStopSequences = 8191
+
+// ErrTooManyContentBlocks is returned for a message with more content blocks
+// than the limit set with WithMaxContentBlocks.
+var ErrTooManyContentBlocks = errors.New("anthropic: too many content blocks")
+
+// Validate checks params for problems that can be detected locally, such as
+// content the model does not support.
+func (p MessageCreateParams) Validate() error {
+	return p.validate(0)
+}
+
+// validate is Validate, also limiting each message to maxContentBlocks content
+// blocks unless it is zero.
+func (p MessageCreateParams) validate(maxContentBlocks int) error {
+	if maxContentBlocks > 0 {
+		for i, msg := range p.Messages {
+			if n := len(msg.ContentBlocks); n > maxContentBlocks {
+				return fmt.Errorf("%w: message %d has %d, exceeding the limit of %d", ErrTooManyContentBlocks, i, n, maxContentBlocks)
+			}
+		}
+	}
+
+	if err := validateTools(p.Tools, p.ToolChoice); err != nil {
+		return err
+	}
+	if err := validateThinking(p.Thinking, p.MaxTokens); err != nil {
+		return err
+	}
+	if n := len(p.StopSequences); n > MaxStopSequences {
+		return fmt.Errorf("anthropic: %d stop sequences, exceeding the limit of %d", n, MaxStopSequences)
+	}
+
+	caps, known := modelCapabilities[p.Model]
+	if !known {
+		return nil
+	}
+
+	if len(p.Tools) > 0 && !caps.Tools {
+		return fmt.Errorf("anthropic: model %s does not support tools", p.Model)
+	}
+
+	for i, msg := range p.Messages {
+		for _, block := range msg.ContentBlocks {
+			switch {
+			case block.Type == ContentBlockTypeImage && !caps.Vision:
+				return fmt.Errorf("anthropic: message %d: model %s does not support image input", i, p.Model)
+			case block.Type == ContentBlockTypeDocument && block.Source != nil && block.Source.MediaType == "application/pdf" && !caps.PDF:
+				return fmt.Errorf("anthropic: message %d: model %s does not support PDF input", i, p.Model)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeStopSequences returns a new slice holding the stop sequences of
+// request followed by those of defaults, without duplicates.
+func mergeStopSequences(request, defaults []string) []string {
+	merged := make([]string, 0, len(request)+len(defaults))
+	seen := make(map[string]bool, len(request)+len(defaults))
+	for _, list := range [][]string{request, defaults} {
+		for _, seq := range list {
+			if !seen[seq] {
+				seen[seq] = true
+				merged = append(merged, seq)
+			}
+		}
+	}
+	return merged
+}
+
+// applyDefaults fills in fields left unset in params from the client's
+// defaults.
+func (c *Client) applyDefaults(ctx context.Context, params MessageCreateParams) MessageCreateParams {
+	if params.Model == "" {
+		params.Model = c.defaultModel
+	}
+	if params.System == "" {
+		if c.defaultSystemFn != nil {
+			params.System = c.defaultSystemFn(ctx)
+		} else {
+			params.System = c.defaultSystem
+		}
+	}
+	if params.MaxTokens == 0 {
+		params.MaxTokens = c.defaultMaxTokens
+	}
+	if len(c.defaultMetadata) > 0 {
+		// merged into a new map so the caller's is left untouched
+		metadata := make(map[string]string, len(c.defaultMetadata)+len(params.Metadata))
+		for k, v := range c.defaultMetadata {
+			metadata[k] = v
+		}
+		for k, v := range params.Metadata {
+			metadata[k] = v
+		}
+		params.Metadata = metadata
+	}
+	if len(c.defaultStops) > 0 {
+		params.StopSequences = mergeStopSequences(params.StopSequences, c.defaultStops)
+	}
+	return c.applyThinkingDefaults(params)
+}
+
+// CreateMessage sends a message and returns the model's response. With
+// WithModelFallback, an overloaded or missing model is retried with the next
+// model in the chain, which ResponseMeta.FallbackOccurred reports.
+func (c *Client) CreateMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*Message, error) {
+	params = c.applyDefaults(ctx, params)
+	if c.canShare(params, opts) {
+		return c.createMessageShared(ctx, params)
+	}
+	return c.createMessageFallback(ctx, params, opts...)
+}
+
+// createMessageFallback sends params, falling back through the model chain.
+// Each model is tried at most once, so a chain that repeats a model, or
+// includes the requested one, can't loop.
+func (c *Client) createMessageFallback(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*Message, error) {
+	requested := params.Model
+	tried := map[string]bool{params.Model: true}
+	msg, err := c.createMessage(ctx, params, opts...)
+	for _, model := range c.fallbacksFor(params.Model) {
+		if !shouldFallback(err) {
+			break
+		}
+		if tried[model] {
+			continue
+		}
+		tried[model] = true
+		params.Model = model
+		msg, err = c.createMessage(ctx, params, opts...)
+	}
+	if meta := responseMetaFrom(opts); meta != nil {
+		meta.FallbackOccurred = params.Model != requested
+		meta.RequestedModel = requested
+	}
+	return msg, err
+}
+
+func (c *Client) createMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*Message, error) {
+	if err := params.validate(c.maxContentBlocks); err != nil {
+		return nil, err
+	}
+	if c.autoStream || optionsConfig(opts).autoStream {
+		msg, err := c.createMessageStreamed(ctx, params, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return c.validateResponse(msg)
+	}
+
+	if c.dryRun != nil {
+		opts = prependOptions(withDryRun(), opts)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages", nil, params, prependOptions(withModel(params.Model), c.betaOptions(params, opts))...)
+	if err != nil {
+		return nil, err
+	}
+	if c.dryRun != nil {
+		return nil, c.logDryRun(req)
+	}
+
+	var msg Message
+	_, err = c.do(req, &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.validateResponse(&msg)
+}
+
+// validateResponse passes msg to the WithResponseValidator function, if any.
+func (c *Client) validateResponse(msg *Message) (*Message, error) {
+	if c.responseValidator != nil {
+		if err := c.responseValidator(msg); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
 }
 
-func (c *Client) CreateMessage(ctx context.Context, params MessageCreateParams) (*Message, error) {
-	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages", params)
+// CreateMessageRaw sends body, an already serialized MessageCreateParams
+// payload, exactly as given.
+func (c *Client) CreateMessageRaw(ctx context.Context, body json.RawMessage, opts ...RequestOption) (*Message, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages", nil, body, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -107,121 +414,414 @@ func (c *Client) CreateMessage(ctx context.Context, params MessageCreateParams)
 	return &msg, nil
 }
 
-func (c *Client) StreamMessage(ctx context.Context, params MessageCreateParams) (*MessageStream, error) {
+func (c *Client) StreamMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*MessageStream, error) {
+	params = c.applyDefaults(ctx, params)
 	params.Stream = true
+	if err := params.validate(c.maxContentBlocks); err != nil {
+		return nil, err
+	}
 
-	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages", params)
+	if c.dryRun != nil {
+		opts = prependOptions(withDryRun(), opts)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages", nil, params, prependOptions(withModel(params.Model), c.betaOptions(params, opts))...)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", c.streamAccept)
-
-	resp, err := c.httpClient.Do(req)
+	if c.dryRun != nil {
+		return nil, c.logDryRun(req)
+	}
+	started := c.clock.Now()
+	resp, err := c.sendStream(req)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode >= http.StatusBadRequest {
-		resp.Body.Close()
-		return nil, fmt.Errorf("anthropic: %s", resp.Status)
+	stream := StreamFromReader(resp.Body)
+	if c.backend != nil {
+		stream.decoder = c.backend.newDecoder(resp.Body)
+	}
+	stream.emitPings = c.emitPings
+	stream.ctx = ctx
+	stream.tracker = newStreamTracker(c.clock, started)
+	if cfg := requestConfigFrom(req.Context()); cfg != nil && cfg.progress != nil {
+		stream.progress = streamProgress{fn: cfg.progress, interval: cfg.progressInterval}
 	}
+	return stream, nil
+}
+
+// StreamText streams a message and delivers only its text, one delta at a
+// time. The text channel is closed at the end of the turn; the error channel
+// then receives the error that ended the stream, if any, and is closed too.
+// Canceling ctx stops the stream for consumers that stop reading early.
+func (c *Client) StreamText(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (<-chan string, <-chan error) {
+	text := make(chan string, textChanBuffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(text)
+
+		stream, err := c.StreamMessage(ctx, params, opts...)
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer stream.Close()
+
+		for stream.Next() {
+			event := stream.Event()
+			if event.Type != StreamEventContentBlockDelta || event.ContentBlock == nil || event.ContentBlock.Text == "" {
+				continue
+			}
+			select {
+			case text <- event.ContentBlock.Text:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+		if err := stream.Err(); err != nil {
+			errc <- err
+		}
+	}()
+
+	return text, errc
+}
 
+// StreamFromReader returns a MessageStream that reads server-sent events from
+// r, such as a recorded transcript or a response obtained outside the client.
+// Closing the stream closes r.
+func StreamFromReader(r io.ReadCloser) *MessageStream {
+	clock := RealClock()
 	return &MessageStream{
-		resp:                resp,
-		reader:              bufio.NewReader(resp.Body),
+		body:                r,
+		decoder:             newSSEDecoder(r),
 		ignoreUnknownEvents: true,
-	}, nil
+		ctx:                 context.Background(),
+		closed:              make(chan struct{}),
+		tracker:             newStreamTracker(clock, clock.Now()),
+	}
 }
 
+// MessageStream reads the events of a streamed message. The usual way to
+// consume it is the Next loop, which separates a clean end from an error:
+//
+//	for stream.Next() {
+//		event := stream.Event()
+//		// ...
+//	}
+//	if err := stream.Err(); err != nil {
+//		// ...
+//	}
+//
+// Recv returns the same events one call at a time, reporting the end of the
+// stream as io.EOF.
 type MessageStream struct {
-	resp                *http.Response
-	reader              *bufio.Reader
+	body                io.ReadCloser
+	decoder             eventDecoder
 	event               MessageStreamEvent
+	lookahead           *MessageStreamEvent
+	lookaheadErr        error
 	ignoreUnknownEvents bool
+	emitPings           bool
+	stopOnToolUse       bool
+	pendingToolUse      *ContentBlock
+	done                bool
+	usage               Usage
+	sinks               []StreamSink
+	sinkErrs            []error
+	current             *MessageStreamEvent
+	answer              answerText
+	progress            streamProgress
+	stopReason          StopReason
+	meter               *RateMeter
+	tracker             streamTracker
+	scratch             streamScratch
+	// ctx is the request's context; closed is closed by Close. Either ends
+	// a TextChan producer the consumer has stopped reading from.
+	ctx       context.Context
+	closed    chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// streamScratch holds decode targets recv reuses from event to event. Nothing
+// in them may be handed out with an event.
+type streamScratch struct {
+	block ContentBlockEvent
+	delta ContentBlockDelta
 }
 
+// textChanBuffer is how many text deltas TextChan buffers, so the parser can
+// run ahead of a slow consumer.
+const textChanBuffer = 64
+
 func (s *MessageStream) Close() error {
-	return s.resp.Body.Close()
+	s.closeOnce.Do(func() { close(s.closed) })
+	return s.body.Close()
 }
 
 func (s *MessageStream) ErrorUnknownEvent() {
 	s.ignoreUnknownEvents = false
 }
 
-func (s *MessageStream) Recv() (*MessageStreamEvent, error) {
-	var eventType StreamEvent
-	var data strings.Builder
+// EmitPings makes Recv return ping events, which are dropped by default, for
+// this stream alone; WithEmitPings does the same for every stream.
+func (s *MessageStream) EmitPings() {
+	s.emitPings = true
+}
 
-	for {
-		line, err := s.reader.ReadString('\n')
+// StopOnToolUse ends the stream at the first tool_use block: the next Recv
+// after its content_block_start returns a message_stop event made up by the
+// client, with StopReasonToolUse as the stop reason, and the connection is
+// closed. The block, whose input never arrives, is then available from
+// PendingToolUse.
+func (s *MessageStream) StopOnToolUse() {
+	s.stopOnToolUse = true
+}
+
+// PendingToolUse returns the tool_use block that ended a stream set to
+// StopOnToolUse, or nil if there was none.
+func (s *MessageStream) PendingToolUse() *ContentBlock {
+	return s.pendingToolUse
+}
+
+// stopAtToolUse ends the stream after the content_block_start of the pending
+// tool_use block, returning a synthetic message_stop.
+func (s *MessageStream) stopAtToolUse() (*MessageStreamEvent, error) {
+	s.done = true
+	s.stopReason = StopReasonToolUse
+	s.body.Close()
+	s.event.Type = StreamEventMessageStop
+	s.event.ContentBlock = nil
+	s.event.Index = 0
+	return &s.event, nil
+}
+
+// Done reports whether the message_stop event has been received, meaning the
+// message is complete and the next Recv returns io.EOF.
+func (s *MessageStream) Done() bool {
+	return s.done
+}
+
+// Usage returns the token usage accumulated so far: the counts from
+// message_start plus those of every message_delta. Once Done reports true it
+// is the final usage of the message.
+func (s *MessageStream) Usage() Usage {
+	return s.usage
+}
+
+// StopReason returns why the model stopped, as reported by the message_delta
+// event at the end of the turn, or "" if it hasn't arrived. A stream can end
+// normally with StopReasonRefusal, which callers should handle distinctly.
+func (s *MessageStream) StopReason() StopReason {
+	return s.stopReason
+}
+
+// ReconnectDelay returns the reconnection delay the server last requested with
+// an SSE retry field, or 0 if it sent none.
+func (s *MessageStream) ReconnectDelay() time.Duration {
+	if d, ok := s.decoder.(*sseDecoder); ok {
+		return d.retry
+	}
+	return 0
+}
+
+// Peek returns the next event without consuming it; the following Recv
+// returns the same event.
+func (s *MessageStream) Peek() (*MessageStreamEvent, error) {
+	if s.lookahead == nil && s.lookaheadErr == nil {
+		event, err := s.recv()
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
+			s.lookaheadErr = err
+		} else {
+			// recv reuses its event, so keep a copy that the next read can't overwrite
+			lookahead := *event
+			s.lookahead = &lookahead
 		}
+	}
+	return s.lookahead, s.lookaheadErr
+}
 
-		line = strings.TrimSpace(line)
-		if line == "" {
-			// skip pings since the caller doesn't care
-			if eventType == StreamEventPing {
-				data.Reset()
+// TextChan consumes the stream in the background and returns a channel that
+// receives the text of each content block delta as it arrives. The channel is
+// closed when the stream ends; Err then reports any error that ended it. A
+// consumer that stops reading early must Close the stream or cancel the
+// request's context, which stops the background reader. Neither Next nor Recv
+// may be called once TextChan has.
+func (s *MessageStream) TextChan() <-chan string {
+	ch := make(chan string, textChanBuffer)
+	go func() {
+		defer close(ch)
+		for s.Next() {
+			event := s.Event()
+			if event.Type != StreamEventContentBlockDelta || event.ContentBlock == nil || event.ContentBlock.Text == "" {
 				continue
 			}
-			break
+			select {
+			case ch <- event.ContentBlock.Text:
+			case <-s.closed:
+				return
+			case <-s.ctx.Done():
+				s.body.Close()
+				return
+			}
 		}
+	}()
+	return ch
+}
 
-		parts := strings.SplitN(line, ": ", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid SSE format: %s", line)
+// Next advances the stream to its next event, which Event then returns. It
+// returns false once the stream ends, whether cleanly or with an error; Err
+// tells the two apart. Next reads through Recv, so the two may be mixed.
+func (s *MessageStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	event, err := s.Recv()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			s.err = err
 		}
+		s.current = nil
+		return false
+	}
+	s.current = event
+	return true
+}
 
-		field, value := parts[0], parts[1]
-		switch field {
-		case "event":
-			eventType = StreamEvent(value)
-		case "data":
-			data.WriteString(value)
-			data.WriteString("\n")
-		default:
-			// Ignore unknown fields
+// Event returns the event read by the last call to Next, or nil once Next has
+// returned false. Like those returned by Recv, it is only valid until the next
+// read.
+func (s *MessageStream) Event() *MessageStreamEvent {
+	return s.current
+}
+
+// Err returns the error that ended a Next loop or the channel returned by
+// TextChan, joined with any errors returned by sinks added with StreamTee. It
+// is nil if the stream ended cleanly and is only meaningful once it has.
+func (s *MessageStream) Err() error {
+	return errors.Join(s.err, s.sinkErr())
+}
+
+func (s *MessageStream) Recv() (*MessageStreamEvent, error) {
+	event, err := s.next()
+	switch {
+	case err == nil:
+		if !s.answer.discard {
+			s.answer.observe(event)
+		}
+		now := s.tracker.record(event)
+		if s.done {
+			s.tracker.endAt(now)
 		}
+		s.observeProgress(now)
+		if s.meter != nil && isTextDelta(event) {
+			s.meter.record(now)
+		}
+		if len(s.sinks) > 0 {
+			s.dispatch(event)
+		}
+	case errors.Is(err, io.EOF):
+		// pass on text held back for blocks that never stopped
+		s.answer.flush(true)
+		s.tracker.end()
+	default:
+		s.tracker.end()
+	}
+	return event, err
+}
+
+// next returns the peeked event, if any, or reads the next one.
+func (s *MessageStream) next() (*MessageStreamEvent, error) {
+	if s.lookahead != nil || s.lookaheadErr != nil {
+		event, err := s.lookahead, s.lookaheadErr
+		s.lookahead, s.lookaheadErr = nil, nil
+		return event, err
+	}
+	return s.recv()
+}
+
+func (s *MessageStream) recv() (*MessageStreamEvent, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	if s.pendingToolUse != nil {
+		return s.stopAtToolUse()
+	}
+
+	sse, err := s.decoder.next()
+	// skip pings unless the caller asked for them
+	for err == nil && StreamEvent(sse.Type) == StreamEventPing && !s.emitPings {
+		sse, err = s.decoder.next()
+	}
+	if err != nil {
+		// io.EOF once the stream is exhausted
+		return nil, err
 	}
 
-	if data.Len() > 0 {
+	eventType := StreamEvent(sse.Type)
+	data := sse.Data
+	if len(data) > 0 {
 		s.event.Type = eventType
 		switch eventType {
-		case StreamEventMessageStart, StreamEventMessageStop:
-			if err := json.Unmarshal([]byte(data.String()), &s.event); err != nil {
+		case StreamEventMessageStart:
+			if err := json.Unmarshal(data, &s.event); err != nil {
 				return nil, err
 			}
+			if s.event.Message != nil {
+				s.usage = s.event.Message.Usage
+			}
+		case StreamEventMessageStop:
+			// carries no payload beyond its type
+			s.done = true
 		case StreamEventMessageDelta:
 			var delta MessageDeltaWrapper
-			if err := json.Unmarshal([]byte(data.String()), &delta); err != nil {
+			if err := json.Unmarshal(data, &delta); err != nil {
 				return nil, err
 			}
 			s.event.Delta = &delta.Delta
+			if delta.Delta.StopReason != "" {
+				s.stopReason = delta.Delta.StopReason
+			}
+			if delta.Usage != nil {
+				s.usage.applyDelta(*delta.Usage)
+			}
 			if s.event.Message != nil {
-				s.event.Message.Usage.OutputTokens += delta.Usage.OutputTokens
+				s.event.Message.Usage = s.usage
 			}
 		case StreamEventContentBlockStart, StreamEventContentBlockStop:
-			var contentBlock ContentBlock
-			if err := json.Unmarshal([]byte(data.String()), &contentBlock); err != nil {
+			// the block itself is decoded afresh, as the event hands it out
+			block := &s.scratch.block
+			*block = ContentBlockEvent{}
+			if err := json.Unmarshal(data, block); err != nil {
 				return nil, err
 			}
-			s.event.ContentBlock = &contentBlock
+			// content_block_stop carries only the index
+			s.event.ContentBlock = block.ContentBlock
+			s.event.Index = block.Index
+			if s.stopOnToolUse && block.ContentBlock != nil && block.ContentBlock.Type == ContentBlockTypeToolUse {
+				pending := *block.ContentBlock
+				s.pendingToolUse = &pending
+			}
 		case StreamEventContentBlockDelta:
-			var delta ContentBlockDelta
-			if err := json.Unmarshal([]byte(data.String()), &delta); err != nil {
+			delta := &s.scratch.delta
+			*delta = ContentBlockDelta{}
+			if err := json.Unmarshal(data, delta); err != nil {
 				return nil, err
 			}
 			s.event.ContentBlock = &ContentBlock{
-				Type: delta.Delta.Type,
-				Text: delta.Delta.Text,
+				Type:        ContentBlockType(delta.Delta.Type),
+				Text:        delta.Delta.Text,
+				Thinking:    delta.Delta.Thinking,
+				Signature:   delta.Delta.Signature,
+				PartialJSON: delta.Delta.PartialJSON,
 			}
 			s.event.Index = delta.Index
+		case StreamEventPing:
 		case StreamEventError:
-			return nil, fmt.Errorf("stream error: %s", data.String())
+			return nil, newStreamError(data)
 		default:
 			if !s.ignoreUnknownEvents {
 				return nil, fmt.Errorf("unknown event type: %s", eventType)
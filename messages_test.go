@@ -2,57 +2,856 @@ package anthropic
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
+	"unicode/utf8"
 )
 
-func TestMessages(t *testing.T) {
-	client := NewClient()
-	res, err := client.CreateMessage(context.Background(), MessageCreateParams{
-		Model:     ModelClaude3Sonnet,
-		MaxTokens: 4000,
-		System:    "You are in test mode. You're job is to reply Ok to the user message. Only return 'Ok'",
-		Messages: []MessageParam{
-			{Role: RoleUser, Content: "Reply to this with only 'Ok'"},
-		},
+func TestParseStreamEvent(t *testing.T) {
+	for _, e := range AllStreamEvents() {
+		parsed, err := ParseStreamEvent(e.String())
+		assert.NoError(t, err)
+		assert.Equal(t, e, parsed)
+		assert.True(t, e.IsValid())
+	}
+
+	parsed, err := ParseStreamEvent(" Message_Start ")
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventMessageStart, parsed)
+	assert.Equal(t, "message_start", parsed.String())
+
+	_, err = ParseStreamEvent("message_restart")
+	assert.Error(t, err)
+	assert.False(t, StreamEvent("MESSAGE_START").IsValid())
+}
+
+const testTranscript = "event: message_start\n" +
+	"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n" +
+	"event: content_block_start\n" +
+	"data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+	"event: ping\n" +
+	"data: {\"type\": \"ping\"}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello\"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\", world\"}}\n\n" +
+	"event: content_block_stop\n" +
+	"data: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+	"event: message_delta\n" +
+	"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":7}}\n\n" +
+	"event: message_stop\n" +
+	"data: {\"type\":\"message_stop\"}\n\n"
+
+func newTestStream(t *testing.T, transcript string) *MessageStream {
+	t.Helper()
+	stream := StreamFromReader(io.NopCloser(strings.NewReader(transcript)))
+	t.Cleanup(func() { stream.Close() })
+	return stream
+}
+
+func TestMessageStreamPeek(t *testing.T) {
+	stream := newTestStream(t, testTranscript)
+
+	first, err := stream.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventMessageStart, first.Type)
+
+	again, err := stream.Peek()
+	assert.NoError(t, err)
+	assert.Same(t, first, again)
+
+	recv, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventMessageStart, recv.Type)
+	assert.Equal(t, "msg_01", recv.Message.ID)
+
+	next, err := stream.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventContentBlockStart, next.Type)
+
+	recv, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventContentBlockStart, recv.Type)
+
+	recv, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventContentBlockDelta, recv.Type)
+	assert.Equal(t, "Hello", recv.ContentBlock.Text)
+}
+
+func TestCreateMessageRaw(t *testing.T) {
+	body := json.RawMessage("{\n  \"model\": \"claude-3-haiku-20240307\",\n  \"max_tokens\": 10,\n  \"messages\": [{\"role\": \"user\", \"content\": \"Hi\"}]\n}")
+
+	var gotBody []byte
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "test", r.Header.Get("X-API-Key"))
+		gotBody, _ = io.ReadAll(r.Body)
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Ok"}],"model":"claude-3-haiku-20240307","stop_reason":"end_turn","usage":{"input_tokens":8,"output_tokens":1}}`)
 	})
+
+	msg, err := client.CreateMessageRaw(context.Background(), body)
 	assert.NoError(t, err)
-	assert.NotNil(t, res)
-	assert.Equal(t, res.Content[0].Text, "Ok")
+	assert.Equal(t, []byte(body), gotBody)
+	assert.Equal(t, "Ok", msg.Content[0].Text)
+	assert.Equal(t, 8, msg.Usage.InputTokens)
 }
 
-func TestMessagesStream(t *testing.T) {
-	client := NewClient()
-	res, err := client.StreamMessage(context.Background(), MessageCreateParams{
-		Model:     ModelClaude3Sonnet,
-		MaxTokens: 4000,
-		System:    "You are in test mode. You're job is to reply Ok to the user message. Only return 'Ok'",
+func TestMessageStreamPings(t *testing.T) {
+	collect := func(stream *MessageStream) []StreamEvent {
+		var events []StreamEvent
+		for {
+			m, err := stream.Recv()
+			if !assert.NoError(t, err) {
+				return events
+			}
+			events = append(events, m.Type)
+			if m.Type == StreamEventMessageStop {
+				return events
+			}
+		}
+	}
+
+	assert.NotContains(t, collect(newTestStream(t, testTranscript)), StreamEventPing)
+
+	stream := newTestStream(t, testTranscript)
+	stream.EmitPings()
+	stream.ErrorUnknownEvent()
+	assert.Contains(t, collect(stream), StreamEventPing)
+}
+
+func TestMessageStreamMultilineData(t *testing.T) {
+	stream := newTestStream(t, "event: message_start\n"+
+		"data: {\"type\":\"message_start\",\n"+
+		"data: \"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n"+
+		"event: error\n"+
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+
+	m, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "msg_01", m.Message.ID)
+
+	_, err = stream.Recv()
+	assert.EqualError(t, err, "anthropic: overloaded_error: Overloaded")
+}
+
+func TestMessageStreamRetryField(t *testing.T) {
+	stream := newTestStream(t, "retry: 3000\n\n"+
+		"event: message_start\n"+
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n"+
+		"retry: soon\n\n"+
+		"event: content_block_delta\n"+
+		"retry: 1500\n"+
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n"+
+		"retry: -200\n\n"+
+		"retry: 1e3\n\n"+
+		"event: message_stop\n"+
+		"data: {\"type\":\"message_stop\"}\n\n")
+	assert.Zero(t, stream.ReconnectDelay())
+
+	m, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventMessageStart, m.Type)
+	assert.Equal(t, 3*time.Second, stream.ReconnectDelay())
+
+	// the garbage value is ignored and the retry-only block is not an event
+	m, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventContentBlockDelta, m.Type)
+	assert.Equal(t, "Hi", m.ContentBlock.Text)
+	assert.Equal(t, 1500*time.Millisecond, stream.ReconnectDelay())
+
+	m, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, StreamEventMessageStop, m.Type)
+	assert.Equal(t, 1500*time.Millisecond, stream.ReconnectDelay())
+}
+
+func TestMessageStreamDone(t *testing.T) {
+	stream := newTestStream(t, testTranscript)
+
+	for !stream.Done() {
+		m, err := stream.Recv()
+		if !assert.NoError(t, err) {
+			return
+		}
+		if stream.Done() {
+			assert.Equal(t, StreamEventMessageStop, m.Type)
+		}
+	}
+
+	m, err := stream.Recv()
+	assert.Nil(t, m)
+	assert.ErrorIs(t, err, io.EOF)
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMessageStreamNext(t *testing.T) {
+	stream := newTestStream(t, testTranscript)
+
+	var events []StreamEvent
+	for stream.Next() {
+		events = append(events, stream.Event().Type)
+	}
+	assert.NoError(t, stream.Err())
+	assert.Nil(t, stream.Event())
+	assert.Equal(t, StreamEventMessageStart, events[0])
+	assert.Equal(t, StreamEventMessageStop, events[len(events)-1])
+
+	// the stream stays ended
+	assert.False(t, stream.Next())
+	assert.NoError(t, stream.Err())
+	_, err := stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMessageStreamNextError(t *testing.T) {
+	stream := newTestStream(t, "event: message_start\n"+
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n"+
+		"event: error\n"+
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"+
+		"event: message_stop\n"+
+		"data: {\"type\":\"message_stop\"}\n\n")
+
+	// Recv and Next read the same events
+	m, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "msg_01", m.Message.ID)
+
+	assert.False(t, stream.Next())
+	assert.Nil(t, stream.Event())
+	var apiErr *APIError
+	if assert.ErrorAs(t, stream.Err(), &apiErr) {
+		assert.Equal(t, "overloaded_error", apiErr.Type)
+		assert.Equal(t, "Overloaded", apiErr.Message)
+		assert.Zero(t, apiErr.StatusCode)
+	}
+
+	// the error ends the stream even though events follow it
+	assert.False(t, stream.Next())
+	assert.Error(t, stream.Err())
+}
+
+func TestMessageStreamStopReason(t *testing.T) {
+	stream := newTestStream(t, testTranscript)
+	for stream.Next() {
+		assert.Empty(t, stream.StopReason(), "set only at the end of the turn")
+		if stream.Event().Type == StreamEventContentBlockStop {
+			break
+		}
+	}
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, StopReasonEndTurn, stream.StopReason())
+
+	stream = newTestStream(t, "event: message_start\n"+
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n"+
+		"event: message_delta\n"+
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"refusal\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":3}}\n\n"+
+		"event: message_stop\n"+
+		"data: {\"type\":\"message_stop\"}\n\n")
+	var delta *MessageDelta
+	for stream.Next() {
+		if event := stream.Event(); event.Type == StreamEventMessageDelta {
+			delta = event.Delta
+		}
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, StopReasonRefusal, stream.StopReason())
+	if assert.NotNil(t, delta) {
+		assert.Equal(t, StopReasonRefusal, delta.StopReason)
+	}
+}
+
+func TestMessageStreamUsage(t *testing.T) {
+	stream := newTestStream(t, "event: message_start\n"+
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"usage\":{\"input_tokens\":12,\"output_tokens\":1,\"cache_creation_input_tokens\":200,\"cache_read_input_tokens\":1000}}}\n\n"+
+		"event: message_delta\n"+
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":null,\"stop_sequence\":null},\"usage\":{\"output_tokens\":4}}\n\n"+
+		"event: message_delta\n"+
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null},\"usage\":{\"input_tokens\":12,\"output_tokens\":11,\"cache_creation_input_tokens\":200,\"cache_read_input_tokens\":1000}}\n\n"+
+		"event: message_delta\n"+
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null}}\n\n"+
+		"event: message_stop\n"+
+		"data: {\"type\":\"message_stop\"}\n\n")
+
+	var last *MessageStreamEvent
+	for stream.Next() {
+		last = stream.Event()
+	}
+	if !assert.NoError(t, stream.Err()) {
+		return
+	}
+
+	// each delta restates the totals so far, which aren't counted twice
+	want := Usage{
+		InputTokens:              12,
+		OutputTokens:             11,
+		CacheCreationInputTokens: 200,
+		CacheReadInputTokens:     1000,
+	}
+	assert.True(t, stream.Done())
+	assert.Equal(t, want, stream.Usage())
+	assert.Equal(t, StreamEventMessageStop, last.Type)
+	assert.Equal(t, want, last.Message.Usage)
+}
+
+func TestUsageApplyDelta(t *testing.T) {
+	u := Usage{InputTokens: 12, OutputTokens: 1, CacheReadInputTokens: 1000}
+	u.applyDelta(Usage{OutputTokens: 4})
+	assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 4, CacheReadInputTokens: 1000}, u)
+	u.applyDelta(Usage{InputTokens: 15, OutputTokens: 9, CacheCreationInputTokens: 200})
+	assert.Equal(t, Usage{InputTokens: 15, OutputTokens: 9, CacheCreationInputTokens: 200, CacheReadInputTokens: 1000}, u)
+}
+
+func TestMessageCreateParamsValidateCapabilities(t *testing.T) {
+	image := MessageParam{
+		Role:          RoleUser,
+		Content:       "What is in this image?",
+		ContentBlocks: []ContentBlockParam{NewImageBlock("image/png", "iVBORw0KGgo=")},
+	}
+	pdf := MessageParam{Role: RoleUser, ContentBlocks: []ContentBlockParam{NewPDFBlock("JVBERi0=")}}
+
+	err := MessageCreateParams{Model: ModelClaude21, MaxTokens: 10, Messages: []MessageParam{image}}.Validate()
+	assert.EqualError(t, err, "anthropic: message 0: model claude-2.1 does not support image input")
+
+	err = MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{pdf}}.Validate()
+	assert.EqualError(t, err, "anthropic: message 0: model claude-3-haiku-20240307 does not support PDF input")
+
+	assert.NoError(t, MessageCreateParams{Model: ModelClaude3Haiku, Messages: []MessageParam{image}}.Validate())
+	err = MessageCreateParams{Model: ModelClaude35Sonnet20240620, MaxTokens: 10, Messages: []MessageParam{pdf}}.Validate()
+	assert.EqualError(t, err, "anthropic: message 0: model claude-3-5-sonnet-20240620 does not support PDF input")
+
+	assert.NoError(t, MessageCreateParams{Model: ModelClaude35Sonnet, Messages: []MessageParam{image}}.Validate())
+	assert.NoError(t, MessageCreateParams{Model: "claude-next", Messages: []MessageParam{image, pdf}}.Validate())
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must be rejected locally")
+	})
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude21, MaxTokens: 10, Messages: []MessageParam{image}})
+	assert.Error(t, err)
+	_, err = client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude21, MaxTokens: 10, Messages: []MessageParam{image}})
+	assert.Error(t, err)
+}
+
+func TestWithMaxContentBlocks(t *testing.T) {
+	blocks := make([]ContentBlockParam, 4)
+	for i := range blocks {
+		blocks[i] = NewTextBlock("chunk")
+	}
+	params := MessageCreateParams{
+		Model:     ModelClaude3Haiku,
+		MaxTokens: 10,
 		Messages: []MessageParam{
-			{Role: RoleUser, Content: "Reply to this with only 'Ok'"},
+			{Role: RoleUser, Content: "Hi"},
+			{Role: RoleAssistant, Content: "Hello"},
+			{Role: RoleUser, ContentBlocks: blocks},
 		},
-	})
+	}
+	assert.NoError(t, params.Validate())
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must be rejected locally")
+	}, WithMaxContentBlocks(3))
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.ErrorIs(t, err, ErrTooManyContentBlocks)
+	assert.EqualError(t, err, "anthropic: too many content blocks: message 2 has 4, exceeding the limit of 3")
+	_, err = client.StreamMessage(context.Background(), params)
+	assert.ErrorIs(t, err, ErrTooManyContentBlocks)
+	assert.ErrorIs(t, NewBatchBuilder(client).Add("req-1", params), ErrTooManyContentBlocks)
+
+	params.Messages[2].ContentBlocks = blocks[:3]
+	assert.NoError(t, params.validate(3))
+}
+
+func TestWithResponseValidator(t *testing.T) {
+	response := `{"id":"msg_01","type":"message","role":"assistant","content":[]}`
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, response)
+	}, WithResponseValidator(func(msg *Message) error {
+		if len(msg.Content) == 0 {
+			return errors.New("empty response")
+		}
+		msg.Content[0].Text = strings.ReplaceAll(msg.Content[0].Text, "555-0100", "[redacted]")
+		return nil
+	}))
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	msg, err := client.CreateMessage(context.Background(), params)
+	assert.EqualError(t, err, "empty response")
+	assert.Nil(t, msg)
+
+	response = `{"id":"msg_02","type":"message","role":"assistant","content":[{"type":"text","text":"Call 555-0100."}]}`
+	msg, err = client.CreateMessage(context.Background(), params)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Call [redacted].", msg.Text())
+	}
+}
+
+func TestMessageStreamBOM(t *testing.T) {
+	stream := newTestStream(t, "\xef\xbb\xbf"+testTranscript)
+
+	m, err := stream.Recv()
 	assert.NoError(t, err)
-	assert.NotNil(t, res)
+	assert.Equal(t, StreamEventMessageStart, m.Type)
+	assert.Equal(t, "msg_01", m.Message.ID)
+}
 
-	content := ""
+func TestMessageStreamTextChan(t *testing.T) {
+	stream := newTestStream(t, testTranscript)
+
+	var text []string
+	for delta := range stream.TextChan() {
+		text = append(text, delta)
+	}
+	assert.Equal(t, []string{"Hello", ", world"}, text)
+	assert.NoError(t, stream.Err())
+
+	stream = newTestStream(t, "event: content_block_delta\n"+
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n"+
+		"event: error\n"+
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+
+	text = nil
+	for delta := range stream.TextChan() {
+		text = append(text, delta)
+	}
+	assert.Equal(t, []string{"Hel"}, text)
+	assert.ErrorContains(t, stream.Err(), "overloaded_error")
+}
+
+func TestMessageStreamTextChanAbandoned(t *testing.T) {
+	for name, stop := range map[string]func(stream *MessageStream, cancel context.CancelFunc){
+		"close":  func(stream *MessageStream, cancel context.CancelFunc) { stream.Close() },
+		"cancel": func(stream *MessageStream, cancel context.CancelFunc) { cancel() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// far more deltas than the channel buffers
+			pr, pw := io.Pipe()
+			go func() {
+				io.WriteString(pw, benchTranscript)
+				pw.Close()
+			}()
+			stream := StreamFromReader(pr)
+			stream.ctx = ctx
+			before := runtime.NumGoroutine()
+
+			ch := stream.TextChan()
+			assert.Equal(t, "the quick brown fox ", <-ch)
+			stop(stream, cancel)
+			// the producer, and the writer it read from, exit without the channel
+			// being drained; poll inline, as assert.Eventually adds a goroutine
+			deadline := time.Now().Add(time.Second)
+			for runtime.NumGoroutine() >= before && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			assert.Less(t, runtime.NumGoroutine(), before)
+		})
+	}
+}
+
+func TestMessageStreamEOF(t *testing.T) {
+	// a stream that ends without message_stop, e.g. cut off by a proxy
+	stream := newTestStream(t, "event: message_start\n"+
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n"+
+		"event: content_block_delta\n"+
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n")
+
+	var events []StreamEvent
 	for {
-		m, err := res.Recv()
+		m, err := stream.Recv()
 		if errors.Is(err, io.EOF) {
+			assert.Nil(t, m)
 			break
 		}
-		assert.NoError(t, err)
-		assert.NotNil(t, m)
+		if !assert.NoError(t, err) {
+			return
+		}
+		events = append(events, m.Type)
+	}
+	assert.Equal(t, []StreamEvent{StreamEventMessageStart, StreamEventContentBlockDelta}, events)
 
-		if m.Delta != nil && m.Delta.StopReason != "" {
-			break
+	_, err := stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestStreamMessage(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		assert.True(t, params.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	}, WithEmitPings())
+
+	stream, err := client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	var events []StreamEvent
+	for stream.Next() {
+		events = append(events, stream.Event().Type)
+	}
+	assert.NoError(t, stream.Err())
+	assert.Contains(t, events, StreamEventPing)
+	assert.Equal(t, StreamEventMessageStop, events[len(events)-1])
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestStreamFromReader(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader(testTranscript)}
+	stream := StreamFromReader(body)
+
+	var text strings.Builder
+	for stream.Next() {
+		if m := stream.Event(); m.Type == StreamEventContentBlockDelta {
+			text.WriteString(m.ContentBlock.Text)
 		}
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, "Hello, world", text.String())
+
+	assert.NoError(t, stream.Close())
+	assert.True(t, body.closed)
+}
+
+func TestStreamText(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	})
+
+	text, errc := client.StreamText(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	var chunks []string
+	for chunk := range text {
+		chunks = append(chunks, chunk)
+	}
+	assert.NoError(t, <-errc)
+	assert.Equal(t, []string{"Hello", ", world"}, chunks)
+	assert.Equal(t, "Hello, world", strings.Join(chunks, ""))
+}
+
+func TestStreamTextError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: required"}}`)
+	})
+
+	text, errc := client.StreamText(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku})
+	_, open := <-text
+	assert.False(t, open)
+	var apiErr *APIError
+	assert.ErrorAs(t, <-errc, &apiErr)
+}
+
+func TestWithDefaultMetadata(t *testing.T) {
+	var got []map[string]string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		got = append(got, params.Metadata)
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[]}`)
+	}, WithDefaultMetadata(map[string]string{"user_id": "service-account", "team": "search"}))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+
+	metadata := map[string]string{"user_id": "user-123"}
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Metadata: metadata})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []map[string]string{
+		{"user_id": "service-account", "team": "search"},
+		{"user_id": "user-123", "team": "search"},
+	}, got)
+	assert.Equal(t, map[string]string{"user_id": "user-123"}, metadata, "caller's map must not be modified")
+}
+
+func TestWithDefaultStopSequences(t *testing.T) {
+	var got [][]string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		got = append(got, params.StopSequences)
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[]}`)
+	}, WithDefaultStopSequences("<END>", "\n\nHuman:"))
+
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+
+	stops := make([]string, 2, 4)
+	copy(stops, []string{"</answer>", "<END>"})
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, StopSequences: stops})
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"<END>", "\n\nHuman:"},
+		{"</answer>", "<END>", "\n\nHuman:"},
+	}, got)
+	assert.Equal(t, []string{"</answer>", "<END>"}, stops, "caller's slice must not be modified")
+	assert.Equal(t, []string{"</answer>", "<END>", ""}, stops[:3], "caller's spare capacity must not be written")
 
-		if m.ContentBlock != nil {
-			content += m.ContentBlock.Text
+	// the limit applies to the merged list
+	many := make([]string, MaxStopSequences-1)
+	for i := range many {
+		many[i] = fmt.Sprintf("stop-%d", i)
+	}
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, StopSequences: many})
+	assert.EqualError(t, err, fmt.Sprintf("anthropic: %d stop sequences, exceeding the limit of %d", MaxStopSequences+1, MaxStopSequences))
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, StopSequences: append(many[:MaxStopSequences-2:MaxStopSequences-2], "<END>")})
+	assert.NoError(t, err)
+	assert.Len(t, got[len(got)-1], MaxStopSequences)
+}
+
+type systemPromptKey struct{}
+
+func TestWithDefaultSystem(t *testing.T) {
+	var systems []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		systems = append(systems, params.System)
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[]}`)
+	}
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	client := newTestClient(t, handler, WithDefaultSystem("You are a helpful assistant."))
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	overridden := params
+	overridden.System = "Reply in French."
+	_, err = client.CreateMessage(context.Background(), overridden)
+	assert.NoError(t, err)
+
+	client = newTestClient(t, handler,
+		WithDefaultSystem("unused"),
+		WithDefaultSystemFn(func(ctx context.Context) string {
+			return "Today is " + ctx.Value(systemPromptKey{}).(string) + "."
+		}))
+	ctx := context.WithValue(context.Background(), systemPromptKey{}, "2024-06-01")
+	_, err = client.CreateMessage(ctx, params)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"You are a helpful assistant.", "Reply in French.", "Today is 2024-06-01."}, systems)
+}
+
+func TestMessageStreamStopOnToolUse(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader(thinkingToolTranscript)}
+	stream := StreamFromReader(body)
+	stream.StopOnToolUse()
+
+	var events []StreamEvent
+	for stream.Next() {
+		events = append(events, stream.Event().Type)
+		if stream.Event().Type == StreamEventContentBlockStart && stream.Event().Index == 2 {
+			assert.NotNil(t, stream.PendingToolUse())
+			assert.False(t, body.closed)
+		}
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, []StreamEvent{
+		StreamEventMessageStart,
+		StreamEventContentBlockStart, StreamEventContentBlockDelta, StreamEventContentBlockDelta, StreamEventContentBlockStop,
+		StreamEventContentBlockStart, StreamEventContentBlockDelta, StreamEventContentBlockDelta, StreamEventContentBlockStop,
+		StreamEventContentBlockStart,
+		StreamEventMessageStop,
+	}, events)
+	assert.True(t, body.closed)
+	assert.True(t, stream.Done())
+	assert.Equal(t, StopReasonToolUse, stream.StopReason())
+	assert.Equal(t, "Let me check the weather.", stream.Text())
+
+	pending := stream.PendingToolUse()
+	if assert.NotNil(t, pending) {
+		assert.Equal(t, ContentBlockTypeToolUse, pending.Type)
+		assert.Equal(t, "toolu_01", pending.ID)
+		assert.Equal(t, "get_weather", pending.Name)
+		assert.JSONEq(t, `{}`, string(pending.Input))
+	}
+
+	// without the option the stream runs to the end
+	stream = newTestStream(t, thinkingToolTranscript)
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Nil(t, stream.PendingToolUse())
+}
+
+func TestMessageStreamLineEndings(t *testing.T) {
+	endings := map[string]string{"CRLF": "\r\n", "CR": "\r"}
+	for name, ending := range endings {
+		t.Run(name, func(t *testing.T) {
+			transcript := strings.ReplaceAll(testTranscript, "\n", ending)
+			// one byte at a time, so "\r\n" is split across reads
+			for _, r := range []io.Reader{strings.NewReader(transcript), iotest.OneByteReader(strings.NewReader(transcript))} {
+				stream := StreamFromReader(io.NopCloser(r))
+				var events []StreamEvent
+				for stream.Next() {
+					events = append(events, stream.Event().Type)
+				}
+				assert.NoError(t, stream.Err())
+				assert.Len(t, events, 7)
+				assert.Equal(t, "Hello, world", stream.Text())
+				assert.Equal(t, StopReasonEndTurn, stream.StopReason())
+			}
+		})
+	}
+}
+
+func TestSSEDecoderMultiLineData(t *testing.T) {
+	d := newSSEDecoder(strings.NewReader("event: a\r\ndata: {\"text\":\r\ndata: \"x\"}\r\n\r\n" +
+		"event: b\rdata: 1\rdata: 2\r\r" +
+		"event: c\ndata: 3\r\n\n"))
+
+	event, err := d.next()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", event.Type)
+	assert.Equal(t, "{\"text\":\n\"x\"}", string(event.Data))
+
+	event, err = d.next()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", event.Type)
+	assert.Equal(t, "1\n2", string(event.Data))
+
+	event, err = d.next()
+	assert.NoError(t, err)
+	assert.Equal(t, "c", event.Type)
+	assert.Equal(t, "3", string(event.Data))
+
+	_, err = d.next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestMessageStreamLongLine(t *testing.T) {
+	// a delta longer than the decoder's read buffer
+	long := strings.Repeat("abcdefgh", 2000)
+	stream := newTestStream(t, strings.Replace(testTranscript, `"text":"Hello"`, `"text":"`+long+`"`, 1))
+
+	var text strings.Builder
+	for stream.Next() {
+		if event := stream.Event(); event.Type == StreamEventContentBlockDelta {
+			text.WriteString(event.ContentBlock.Text)
 		}
 	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, long+", world", text.String())
+}
 
-	assert.Equal(t, "Ok", content)
+// benchTranscript is a long streamed response, a message of 1000 text deltas.
+var benchTranscript = func() string {
+	var b strings.Builder
+	b.WriteString("event: message_start\n" +
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n" +
+		"event: content_block_start\n" +
+		"data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+	for i := 0; i < 1000; i++ {
+		b.WriteString("event: content_block_delta\n" +
+			"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"the quick brown fox \"}}\n\n")
+		if i%100 == 0 {
+			b.WriteString("event: ping\ndata: {\"type\": \"ping\"}\n\n")
+		}
+	}
+	b.WriteString("event: content_block_stop\n" +
+		"data: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+		"event: message_delta\n" +
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":4000}}\n\n" +
+		"event: message_stop\n" +
+		"data: {\"type\":\"message_stop\"}\n\n")
+	return b.String()
+}()
+
+func BenchmarkRecv(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchTranscript)))
+	for i := 0; i < b.N; i++ {
+		stream := StreamFromReader(io.NopCloser(strings.NewReader(benchTranscript)))
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err != io.EOF {
+					b.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+}
+
+func FuzzMessageCreateParamsRoundTrip(f *testing.F) {
+	// model, system, role, content, stop, metadata key and value, max_tokens,
+	// top_k, thinking budget, temperature, top_p, stream
+	f.Add("", "", "", "", "", "", "", 0, 0, 0, 0.0, 0.0, false)
+	f.Add(ModelClaude3Haiku, "Be brief.", RoleUser, "Hello", "</answer>", "user_id", "u_123", 1024, 40, 512, 0.7, 0.9, true)
+	f.Add(" ", "\n", RoleAssistant, "\"quoted\" <tag> & \\  ", "\x00", "", "", math.MaxInt, math.MaxInt, math.MaxInt, -0.0, 1e-300, false)
+	f.Add("model", "", "user", "", "", "k", "", -1, math.MinInt, 0, math.MaxFloat64, math.SmallestNonzeroFloat64, true)
+
+	f.Fuzz(func(t *testing.T, model, system, role, content, stop, key, value string, maxTokens, topK, budget int, temperature, topP float64, stream bool) {
+		for _, s := range []string{model, system, role, content, stop, key, value} {
+			if !utf8.ValidString(s) {
+				t.Skip("encoding/json replaces invalid UTF-8")
+			}
+		}
+
+		params := MessageCreateParams{
+			MaxTokens: maxTokens,
+			Messages: []MessageParam{
+				{Role: role, Content: content},
+				{Role: role, ContentBlocks: []ContentBlockParam{NewTextBlock(content), NewTextDocument(system, content)}},
+			},
+			Model:         model,
+			Metadata:      map[string]string{key: value},
+			StopSequences: []string{stop},
+			Stream:        stream,
+			System:        system,
+			Temperature:   temperature,
+			TopK:          topK,
+			TopP:          topP,
+		}
+		if budget != 0 {
+			params.Thinking = &Thinking{Type: "enabled", BudgetTokens: budget}
+		}
+
+		data, err := json.Marshal(params)
+		if err != nil {
+			// NaN and infinite floats have no JSON form
+			if math.IsNaN(temperature) || math.IsInf(temperature, 0) || math.IsNaN(topP) || math.IsInf(topP, 0) {
+				return
+			}
+			t.Fatal(err)
+		}
+		var decoded MessageCreateParams
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("decoding %s: %v", data, err)
+		}
+		if !reflect.DeepEqual(params, decoded) {
+			t.Fatalf("round trip through %s\nsent    %#v\ndecoded %#v", data, params, decoded)
+		}
+	})
 }
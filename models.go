@@ -1,5 +1,12 @@
 package anthropic
 
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
 const (
 	ModelClaude35Sonnet         = "claude-3-5-sonnet-20240620"
 	ModelClaude35Sonnet20240620 = "claude-3-5-sonnet-20240620"
@@ -10,9 +17,168 @@ const (
 	ModelClaude3Opus20240229   = "claude-3-opus-20240229"
 	ModelClaude3Sonnet20240229 = "claude-3-sonnet-20240229"
 	ModelClaude3Haiku20240307  = "claude-3-haiku-20240307"
+
+	ModelClaude21        = "claude-2.1"
+	ModelClaude20        = "claude-2.0"
+	ModelClaudeInstant12 = "claude-instant-1.2"
 )
 
 const (
 	RoleUser      = "user"
 	RoleAssistant = "assistant"
 )
+
+type Capabilities struct {
+	Vision   bool
+	Tools    bool
+	Thinking bool
+	PDF      bool
+}
+
+var modelCapabilities = map[string]Capabilities{
+	ModelClaude35Sonnet20240620: {Vision: true, Tools: true},
+	ModelClaude3Opus20240229:    {Vision: true, Tools: true},
+	ModelClaude3Sonnet20240229:  {Vision: true, Tools: true},
+	ModelClaude3Haiku20240307:   {Vision: true, Tools: true},
+	ModelClaude21:               {},
+	ModelClaude20:               {},
+	ModelClaudeInstant12:        {},
+}
+
+// ModelCapabilities reports the features supported by model. Unknown models
+// report no capabilities and are not checked by MessageCreateParams.Validate.
+func ModelCapabilities(model string) Capabilities {
+	return modelCapabilities[model]
+}
+
+// Tier classifies models by capability and price.
+type Tier string
+
+const (
+	// TierInstant is the fastest and cheapest tier: Haiku and Claude Instant.
+	TierInstant Tier = "instant"
+	// TierStandard balances capability and price: Sonnet and Claude 2.
+	TierStandard Tier = "standard"
+	// TierPremium is the most capable tier: Opus.
+	TierPremium Tier = "premium"
+)
+
+var modelTiers = map[string]Tier{
+	ModelClaude35Sonnet20240620:  TierStandard,
+	"claude-3-5-sonnet-20241022": TierStandard,
+	"claude-3-5-sonnet-latest":   TierStandard,
+	"claude-3-5-haiku-20241022":  TierInstant,
+	"claude-3-5-haiku-latest":    TierInstant,
+	ModelClaude3Opus20240229:     TierPremium,
+	"claude-3-opus-latest":       TierPremium,
+	ModelClaude3Sonnet20240229:   TierStandard,
+	ModelClaude3Haiku20240307:    TierInstant,
+	ModelClaude21:                TierStandard,
+	ModelClaude20:                TierStandard,
+	ModelClaudeInstant12:         TierInstant,
+}
+
+// ModelTier returns the tier of model, which may be a dated snapshot or an
+// alias, or false if the model is unknown.
+func ModelTier(model string) (Tier, bool) {
+	tier, ok := modelTiers[model]
+	return tier, ok
+}
+
+// modelPricing holds the price in USD per million input and output tokens.
+var modelPricing = map[string]struct{ input, output float64 }{
+	ModelClaude35Sonnet20240620: {3, 15},
+	ModelClaude3Opus20240229:    {15, 75},
+	ModelClaude3Sonnet20240229:  {3, 15},
+	ModelClaude3Haiku20240307:   {0.25, 1.25},
+	ModelClaude21:               {8, 24},
+	ModelClaude20:               {8, 24},
+	ModelClaudeInstant12:        {0.8, 2.4},
+}
+
+// estimateCost returns the cost in USD of usage on model, or zero if the
+// model's pricing is unknown.
+func estimateCost(model string, usage Usage) float64 {
+	price := modelPricing[model]
+	return (float64(usage.InputTokens)*price.input + float64(usage.OutputTokens)*price.output) / 1_000_000
+}
+
+// modelContextWindows holds each model's context window in tokens.
+var modelContextWindows = map[string]int{
+	ModelClaude35Sonnet20240620: 200_000,
+	ModelClaude3Opus20240229:    200_000,
+	ModelClaude3Sonnet20240229:  200_000,
+	ModelClaude3Haiku20240307:   200_000,
+	ModelClaude21:               200_000,
+	ModelClaude20:               100_000,
+	ModelClaudeInstant12:        100_000,
+}
+
+// ModelContextWindow returns the context window of model in tokens, or false
+// if the model is unknown and the caller must supply it.
+func ModelContextWindow(model string) (int, bool) {
+	window, ok := modelContextWindows[model]
+	return window, ok
+}
+
+// ModelContextWindow is like the package-level ModelContextWindow but also
+// consults windows set with WithModelContextWindow, which take precedence.
+func (c *Client) ModelContextWindow(model string) (int, bool) {
+	if window, ok := c.contextWindows[model]; ok {
+		return window, true
+	}
+	return ModelContextWindow(model)
+}
+
+type ModelInfo struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// ContextWindow is the model's context window in tokens, filled in by the
+	// client from its known windows. It is zero for unknown models.
+	ContextWindow int `json:"-"`
+}
+
+func (c *Client) ListModels(ctx context.Context, params ListParams, opts ...RequestOption) (*Page[ModelInfo], error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/models", params.values(), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var page Page[ModelInfo]
+	_, err = c.do(req, &page)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Data {
+		page.Data[i].ContextWindow, _ = c.ModelContextWindow(page.Data[i].ID)
+	}
+
+	return &page, nil
+}
+
+func (c *Client) ListModelsAutoPaging(ctx context.Context, params ListParams, opts ...RequestOption) *PageIterator[ModelInfo] {
+	return newListIterator(ctx, params, func(ctx context.Context, params ListParams) (*Page[ModelInfo], error) {
+		return c.ListModels(ctx, params, opts...)
+	})
+}
+
+// GetModel retrieves a model by ID or alias. Aliases such as
+// "claude-3-5-sonnet-latest" resolve to the dated snapshot they point to.
+func (c *Client) GetModel(ctx context.Context, id string, opts ...RequestOption) (*ModelInfo, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/models/"+url.PathEscape(id), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var model ModelInfo
+	_, err = c.do(req, &model)
+	if err != nil {
+		return nil, err
+	}
+	model.ContextWindow, _ = c.ModelContextWindow(model.ID)
+
+	return &model, nil
+}
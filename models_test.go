@@ -0,0 +1,161 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListModelsAutoPaging(t *testing.T) {
+	pages := map[string]string{
+		"":                       `{"data":[{"id":"claude-3-5-sonnet-20240620","type":"model","display_name":"Claude 3.5 Sonnet","created_at":"2024-06-20T00:00:00Z"},{"id":"claude-3-opus-20240229","type":"model","display_name":"Claude 3 Opus","created_at":"2024-02-29T00:00:00Z"}],"has_more":true,"first_id":"claude-3-5-sonnet-20240620","last_id":"claude-3-opus-20240229"}`,
+		"claude-3-opus-20240229": `{"data":[{"id":"claude-3-haiku-20240307","type":"model","display_name":"Claude 3 Haiku","created_at":"2024-03-07T00:00:00Z"}],"has_more":false,"first_id":"claude-3-haiku-20240307","last_id":"claude-3-haiku-20240307"}`,
+	}
+
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		afterID := r.URL.Query().Get("after_id")
+		requests = append(requests, afterID)
+		fmt.Fprint(w, pages[afterID])
+	})
+
+	it := client.ListModelsAutoPaging(context.Background(), ListParams{Limit: 2})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Current().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"claude-3-5-sonnet-20240620", "claude-3-opus-20240229", "claude-3-haiku-20240307"}, ids)
+	assert.Equal(t, []string{"", "claude-3-opus-20240229"}, requests)
+}
+
+func TestListModels(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "claude-3-opus-20240229", r.URL.Query().Get("before_id"))
+		fmt.Fprint(w, `{"data":[{"id":"claude-3-5-sonnet-20240620","type":"model","display_name":"Claude 3.5 Sonnet","created_at":"2024-06-20T00:00:00Z"}],"has_more":false,"first_id":"claude-3-5-sonnet-20240620","last_id":"claude-3-5-sonnet-20240620"}`)
+	})
+
+	page, err := client.ListModels(context.Background(), ListParams{BeforeID: "claude-3-opus-20240229"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "Claude 3.5 Sonnet", page.Data[0].DisplayName)
+	assert.Equal(t, 2024, page.Data[0].CreatedAt.Year())
+	assert.False(t, page.HasMore)
+}
+
+func TestGetModel(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v1/models/claude-3-5-sonnet-20240620", "/v1/models/claude-3-5-sonnet-latest":
+			fmt.Fprint(w, `{"id":"claude-3-5-sonnet-20240620","type":"model","display_name":"Claude 3.5 Sonnet","created_at":"2024-06-20T00:00:00Z"}`)
+		case "/v1/models/bad%2Fmodel":
+			w.Header().Set("request-id", "req_404")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"not_found_error","message":"model: bad/model"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+	})
+
+	model, err := client.GetModel(context.Background(), ModelClaude35Sonnet)
+	assert.NoError(t, err)
+	assert.Equal(t, ModelClaude35Sonnet, model.ID)
+
+	model, err = client.GetModel(context.Background(), "claude-3-5-sonnet-latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "claude-3-5-sonnet-20240620", model.ID)
+
+	_, err = client.GetModel(context.Background(), "bad/model")
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.Equal(t, http.StatusNotFound, notFound.StatusCode)
+	assert.Equal(t, "not_found_error", notFound.Type)
+	assert.Equal(t, "model: bad/model", notFound.Message)
+	assert.Equal(t, "req_404", notFound.RequestID)
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+}
+
+func TestModelCapabilities(t *testing.T) {
+	// PDF support arrived after the June 3.5 Sonnet
+	assert.Equal(t, Capabilities{Vision: true, Tools: true}, ModelCapabilities(ModelClaude35Sonnet))
+	assert.True(t, ModelCapabilities(ModelClaude3Haiku).Vision)
+	assert.False(t, ModelCapabilities(ModelClaude3Haiku).PDF)
+	assert.Equal(t, Capabilities{}, ModelCapabilities(ModelClaude21))
+	assert.Equal(t, Capabilities{}, ModelCapabilities("not-a-model"))
+}
+
+func TestModelTier(t *testing.T) {
+	for model, want := range map[string]Tier{
+		ModelClaude3Haiku:          TierInstant,
+		ModelClaudeInstant12:       TierInstant,
+		"claude-3-5-haiku-latest":  TierInstant,
+		ModelClaude35Sonnet:        TierStandard,
+		ModelClaude3Sonnet20240229: TierStandard,
+		ModelClaude21:              TierStandard,
+		ModelClaude3Opus:           TierPremium,
+		"claude-3-opus-latest":     TierPremium,
+	} {
+		tier, ok := ModelTier(model)
+		assert.True(t, ok, model)
+		assert.Equal(t, want, tier, model)
+	}
+
+	// every model with known capabilities has a tier
+	for model := range modelCapabilities {
+		_, ok := ModelTier(model)
+		assert.True(t, ok, model)
+	}
+
+	_, ok := ModelTier("claude-next")
+	assert.False(t, ok)
+}
+
+func TestModelContextWindow(t *testing.T) {
+	window, ok := ModelContextWindow(ModelClaude3Opus)
+	assert.True(t, ok)
+	assert.Equal(t, 200_000, window)
+
+	window, ok = ModelContextWindow(ModelClaudeInstant12)
+	assert.True(t, ok)
+	assert.Equal(t, 100_000, window)
+
+	_, ok = ModelContextWindow("claude-next")
+	assert.False(t, ok)
+
+	client := NewClient(WithAPIKey("test"), WithModelContextWindow("claude-next", 500_000), WithModelContextWindow(ModelClaude3Haiku, 1_000))
+	window, ok = client.ModelContextWindow("claude-next")
+	assert.True(t, ok)
+	assert.Equal(t, 500_000, window)
+	window, _ = client.ModelContextWindow(ModelClaude3Haiku)
+	assert.Equal(t, 1_000, window)
+	window, _ = client.ModelContextWindow(ModelClaude3Opus)
+	assert.Equal(t, 200_000, window)
+
+	// overrides on a derived client don't leak back
+	derived := client.WithOptions(WithModelContextWindow("claude-other", 10))
+	_, ok = derived.ModelContextWindow("claude-other")
+	assert.True(t, ok)
+	_, ok = client.ModelContextWindow("claude-other")
+	assert.False(t, ok)
+}
+
+func TestModelInfoContextWindow(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"id":"claude-3-5-sonnet-20240620","type":"model"},{"id":"claude-next","type":"model"},{"id":"claude-unknown","type":"model"}],"has_more":false}`)
+	}, WithModelContextWindow("claude-next", 500_000))
+
+	page, err := client.ListModels(context.Background(), ListParams{})
+	if assert.NoError(t, err) && assert.Len(t, page.Data, 3) {
+		assert.Equal(t, 200_000, page.Data[0].ContextWindow)
+		assert.Equal(t, 500_000, page.Data[1].ContextWindow)
+		assert.Zero(t, page.Data[2].ContextWindow)
+	}
+}
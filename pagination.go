@@ -0,0 +1,119 @@
+package anthropic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+type ListParams struct {
+	Limit    int
+	AfterID  string
+	BeforeID string
+}
+
+func (p ListParams) values() url.Values {
+	v := url.Values{}
+	if p.Limit > 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.AfterID != "" {
+		v.Set("after_id", p.AfterID)
+	}
+	if p.BeforeID != "" {
+		v.Set("before_id", p.BeforeID)
+	}
+	return v
+}
+
+type Page[T any] struct {
+	Data    []T    `json:"data"`
+	HasMore bool   `json:"has_more"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+}
+
+// PageIterator walks every item across the pages of a list endpoint, fetching
+// subsequent pages on demand.
+//
+//	it := client.ListModelsAutoPaging(ctx, ListParams{})
+//	for it.Next() {
+//		model := it.Current()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator[T any] struct {
+	ctx     context.Context
+	fetch   func(ctx context.Context, cursor string) (items []T, next string, more bool, err error)
+	items   []T
+	index   int
+	cursor  string
+	more    bool
+	current T
+	err     error
+}
+
+func newPageIterator[T any](ctx context.Context, fetch func(ctx context.Context, cursor string) ([]T, string, bool, error)) *PageIterator[T] {
+	return &PageIterator[T]{ctx: ctx, fetch: fetch, more: true}
+}
+
+// newListIterator pages through an endpoint using the before_id/after_id
+// cursors. Iteration moves backwards when params.BeforeID is set.
+func newListIterator[T any](ctx context.Context, params ListParams, list func(context.Context, ListParams) (*Page[T], error)) *PageIterator[T] {
+	backwards := params.BeforeID != ""
+	first := true
+	return newPageIterator(ctx, func(ctx context.Context, cursor string) ([]T, string, bool, error) {
+		p := params
+		if !first {
+			if backwards {
+				p.BeforeID = cursor
+			} else {
+				p.AfterID = cursor
+			}
+		}
+		first = false
+
+		page, err := list(ctx, p)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if backwards {
+			return page.Data, page.FirstID, page.HasMore, nil
+		}
+		return page.Data, page.LastID, page.HasMore, nil
+	})
+}
+
+func (it *PageIterator[T]) Next() bool {
+	for it.index >= len(it.items) {
+		if it.err != nil || !it.more {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		items, next, more, err := it.fetch(it.ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.items, it.index, it.cursor = items, 0, next
+		// a page claiming more results without a cursor would loop forever
+		it.more = more && next != ""
+	}
+
+	it.current = it.items[it.index]
+	it.index++
+	return true
+}
+
+func (it *PageIterator[T]) Current() T {
+	return it.current
+}
+
+func (it *PageIterator[T]) Err() error {
+	return it.err
+}
@@ -0,0 +1,381 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONState describes how much of a JSON value has been received.
+type JSONState int
+
+const (
+	// JSONIncomplete means the input so far is a valid prefix of a JSON value.
+	JSONIncomplete JSONState = iota
+	// JSONComplete means the input is a single valid JSON value.
+	JSONComplete
+	// JSONInvalid means no continuation of the input can be valid JSON.
+	JSONInvalid
+)
+
+func (s JSONState) String() string {
+	switch s {
+	case JSONIncomplete:
+		return "incomplete"
+	case JSONComplete:
+		return "complete"
+	case JSONInvalid:
+		return "invalid"
+	}
+	return fmt.Sprintf("JSONState(%d)", int(s))
+}
+
+// ToolInputAccumulator assembles the input of a tool_use block from the
+// partial_json of its input_json_delta events, so that a tool call can be
+// shown while it is still forming:
+//
+//	if event.ContentBlock.Type == "input_json_delta" {
+//		acc.Feed(event.ContentBlock.PartialJSON)
+//		path, _ := acc.StringAt("path")
+//	}
+//
+// Preview and the At methods parse the input leniently, closing unterminated
+// strings, arrays and objects and leaving out values that are cut off before
+// they can be read, such as a key without its value or "tru". Value parses it
+// strictly once it is complete.
+//
+// The zero value is ready to use.
+type ToolInputAccumulator struct {
+	buf strings.Builder
+}
+
+// Feed appends a chunk of partial JSON.
+func (a *ToolInputAccumulator) Feed(partialJSON string) {
+	a.buf.WriteString(partialJSON)
+}
+
+// Raw returns the input received so far.
+func (a *ToolInputAccumulator) Raw() string {
+	return a.buf.String()
+}
+
+// State reports whether the input received so far is complete, a prefix of
+// valid JSON, or invalid.
+func (a *ToolInputAccumulator) State() JSONState {
+	raw := a.buf.String()
+	if json.Valid([]byte(raw)) {
+		return JSONComplete
+	}
+	if _, _, err := parsePartialJSON(raw); errors.Is(err, errPartialJSON) {
+		return JSONIncomplete
+	}
+	return JSONInvalid
+}
+
+// Value returns the complete input, or an error if it is incomplete or
+// invalid. An input left empty, as tools without parameters may send, is an
+// empty object.
+func (a *ToolInputAccumulator) Value() (json.RawMessage, error) {
+	raw := a.buf.String()
+	if strings.TrimSpace(raw) == "" {
+		return json.RawMessage("{}"), nil
+	}
+	if !json.Valid([]byte(raw)) {
+		return nil, fmt.Errorf("anthropic: tool input is %s JSON", a.State())
+	}
+	return json.RawMessage(raw), nil
+}
+
+// Preview returns a best-effort decoding of the input so far, using the types
+// encoding/json decodes into an interface{}. It returns nil if nothing can be
+// read yet or the input is invalid.
+func (a *ToolInputAccumulator) Preview() interface{} {
+	v, _, err := parsePartialJSON(a.buf.String())
+	if err != nil && !errors.Is(err, errPartialJSON) {
+		return nil
+	}
+	return v
+}
+
+// At returns the preview value at path, a dot-separated list of object keys
+// and array indexes such as "files.0.name". An empty path is the whole value.
+func (a *ToolInputAccumulator) At(path string) (interface{}, bool) {
+	v := a.Preview()
+	if v == nil {
+		return nil, false
+	}
+	if path == "" {
+		return v, true
+	}
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// StringAt returns the preview string at path, which may still be growing.
+// It reports false if there is no string there yet.
+func (a *ToolInputAccumulator) StringAt(path string) (string, bool) {
+	v, ok := a.At(path)
+	s, isString := v.(string)
+	return s, ok && isString
+}
+
+// errPartialJSON is returned by parsePartialJSON for input cut off before the
+// end of its value.
+var errPartialJSON = errors.New("unexpected end of JSON input")
+
+// parsePartialJSON leniently parses a prefix of a JSON value. ok reports
+// whether any value could be read; err is errPartialJSON if the input ends
+// early.
+func parsePartialJSON(text string) (v interface{}, ok bool, err error) {
+	p := partialParser{text: text}
+	v, ok, err = p.value()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.text) {
+			return nil, false, fmt.Errorf("invalid character %q after top-level value", p.text[p.pos])
+		}
+	}
+	return v, ok, err
+}
+
+type partialParser struct {
+	text string
+	pos  int
+}
+
+func (p *partialParser) skipSpace() {
+	for p.pos < len(p.text) && strings.IndexByte(" \t\r\n", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// value parses the value at the current position. If the input ends first,
+// it returns whatever could be read along with errPartialJSON.
+func (p *partialParser) value() (interface{}, bool, error) {
+	p.skipSpace()
+	if p.pos == len(p.text) {
+		return nil, false, errPartialJSON
+	}
+	switch c := p.text[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		s, ok, err := p.string()
+		return s, ok, err
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	case c == 't':
+		return p.literal("true", true)
+	case c == 'f':
+		return p.literal("false", false)
+	case c == 'n':
+		return p.literal("null", nil)
+	default:
+		return nil, false, fmt.Errorf("invalid character %q looking for beginning of value", c)
+	}
+}
+
+func (p *partialParser) object() (interface{}, bool, error) {
+	p.pos++ // {
+	obj := map[string]interface{}{}
+	for {
+		p.skipSpace()
+		if p.pos == len(p.text) {
+			return obj, true, errPartialJSON
+		}
+		if p.text[p.pos] == '}' && len(obj) == 0 {
+			p.pos++
+			return obj, true, nil
+		}
+		if p.text[p.pos] != '"' {
+			return nil, false, fmt.Errorf("invalid character %q looking for beginning of object key string", p.text[p.pos])
+		}
+		// a key is only used once it is complete
+		key, _, err := p.string()
+		if err != nil {
+			return obj, true, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.text) {
+			return obj, true, errPartialJSON
+		}
+		if p.text[p.pos] != ':' {
+			return nil, false, fmt.Errorf("invalid character %q after object key", p.text[p.pos])
+		}
+		p.pos++
+		v, ok, err := p.value()
+		if ok {
+			obj[key] = v
+		}
+		if err != nil {
+			return obj, true, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.text) {
+			return obj, true, errPartialJSON
+		}
+		switch p.text[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return obj, true, nil
+		default:
+			return nil, false, fmt.Errorf("invalid character %q after object key:value pair", p.text[p.pos])
+		}
+	}
+}
+
+func (p *partialParser) array() (interface{}, bool, error) {
+	p.pos++ // [
+	arr := []interface{}{}
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == ']' {
+		p.pos++
+		return arr, true, nil
+	}
+	for {
+		v, ok, err := p.value()
+		if ok {
+			arr = append(arr, v)
+		}
+		if err != nil {
+			return arr, true, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.text) {
+			return arr, true, errPartialJSON
+		}
+		switch p.text[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return arr, true, nil
+		default:
+			return nil, false, fmt.Errorf("invalid character %q after array element", p.text[p.pos])
+		}
+	}
+}
+
+// string parses a string, returning the part received so far if it is
+// unterminated. An escape sequence cut off partway is left out.
+func (p *partialParser) string() (string, bool, error) {
+	start := p.pos
+	p.pos++ // "
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			var s string
+			if err := json.Unmarshal([]byte(p.text[start:p.pos]), &s); err != nil {
+				return "", false, err
+			}
+			return s, true, nil
+		default:
+			p.pos++
+		}
+	}
+	p.pos = len(p.text)
+
+	var s string
+	if err := json.Unmarshal([]byte(trimPartialEscape(p.text[start:])+`"`), &s); err != nil {
+		return "", false, err
+	}
+	return s, true, errPartialJSON
+}
+
+// trimPartialEscape removes an escape sequence cut off at the end of an
+// unterminated string, then a \u escape for the first half of a surrogate
+// pair whose second half hasn't arrived.
+func trimPartialEscape(s string) string {
+	if last := lastEscape(s); last >= 0 {
+		if escape := s[last:]; len(escape) < 2 || escape[1] == 'u' && len(escape) < 6 {
+			s = s[:last]
+		}
+	}
+	if last := lastEscape(s); last >= 0 && len(s)-last == 6 && s[last+1] == 'u' {
+		if r, err := strconv.ParseUint(s[last+2:], 16, 16); err == nil && r >= 0xd800 && r < 0xdc00 {
+			s = s[:last]
+		}
+	}
+	return s
+}
+
+// lastEscape returns the index of the backslash starting the last escape
+// sequence in s, or -1 if there is none.
+func lastEscape(s string) int {
+	last := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			last = i
+			i++
+		}
+	}
+	return last
+}
+
+// number parses a number. One cut off partway is read as far as it is valid,
+// so "12." reads as 12 and "-" is left out.
+func (p *partialParser) number() (interface{}, bool, error) {
+	start := p.pos
+	for p.pos < len(p.text) && strings.IndexByte("+-0123456789.eE", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+	num := p.text[start:p.pos]
+	if p.pos < len(p.text) {
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil || !json.Valid([]byte(num)) {
+			return nil, false, fmt.Errorf("invalid number %q", num)
+		}
+		return f, true, nil
+	}
+
+	trimmed := strings.TrimRight(num, "+-.eE")
+	if trimmed == "" {
+		return nil, false, errPartialJSON
+	}
+	f, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || !json.Valid([]byte(trimmed)) {
+		return nil, false, fmt.Errorf("invalid number %q", num)
+	}
+	return f, true, errPartialJSON
+}
+
+// literal parses true, false or null, any prefix of which is incomplete.
+func (p *partialParser) literal(word string, v interface{}) (interface{}, bool, error) {
+	rest := p.text[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, word):
+		p.pos += len(word)
+		return v, true, nil
+	case strings.HasPrefix(word, rest):
+		p.pos = len(p.text)
+		return nil, false, errPartialJSON
+	default:
+		return nil, false, fmt.Errorf("invalid literal, expected %s", word)
+	}
+}
@@ -0,0 +1,127 @@
+package anthropic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolInputAccumulator(t *testing.T) {
+	// partial_json chunks as streamed for a write_file call, split mid-key,
+	// mid-escape and mid-number
+	chunks := []string{
+		``,
+		`{"pa`,
+		`th": "src/ma`,
+		`in.go", "content": "line 1\`,
+		`nline 2 \u00`,
+		`e9\"q`,
+		`uoted\""`,
+		`, "mode": 6`,
+		`44, "ratio": 0.`,
+		`5e`,
+		`-1, "overwrite": tr`,
+		`ue, "tags": ["a", `,
+		`"b`,
+		`"]}`,
+	}
+	want := []struct {
+		state   JSONState
+		preview interface{}
+	}{
+		{JSONIncomplete, nil},
+		{JSONIncomplete, map[string]interface{}{}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/ma"}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1"}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 "}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"q"}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\""}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 6.0}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 644.0, "ratio": 0.0}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 644.0, "ratio": 0.5}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 644.0, "ratio": 0.05}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 644.0, "ratio": 0.05, "overwrite": true, "tags": []interface{}{"a"}}},
+		{JSONIncomplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 644.0, "ratio": 0.05, "overwrite": true, "tags": []interface{}{"a", "b"}}},
+		{JSONComplete, map[string]interface{}{"path": "src/main.go", "content": "line 1\nline 2 é\"quoted\"", "mode": 644.0, "ratio": 0.05, "overwrite": true, "tags": []interface{}{"a", "b"}}},
+	}
+
+	var acc ToolInputAccumulator
+	for i, chunk := range chunks {
+		acc.Feed(chunk)
+		assert.Equal(t, want[i].state, acc.State(), "after chunk %d %q", i, chunk)
+		assert.Equal(t, want[i].preview, acc.Preview(), "after chunk %d %q", i, chunk)
+		// nothing at all is an empty object, as tested below
+		if i > 0 && i < len(chunks)-1 {
+			_, err := acc.Value()
+			assert.EqualError(t, err, "anthropic: tool input is incomplete JSON")
+		}
+	}
+
+	value, err := acc.Value()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"path":"src/main.go","content":"line 1\nline 2 é\"quoted\"","mode":644,"ratio":0.05,"overwrite":true,"tags":["a","b"]}`, string(value))
+
+	path, ok := acc.StringAt("path")
+	assert.True(t, ok)
+	assert.Equal(t, "src/main.go", path)
+	tag, ok := acc.StringAt("tags.1")
+	assert.True(t, ok)
+	assert.Equal(t, "b", tag)
+	_, ok = acc.StringAt("mode")
+	assert.False(t, ok)
+	_, ok = acc.StringAt("tags.2")
+	assert.False(t, ok)
+	_, ok = acc.At("path.name")
+	assert.False(t, ok)
+}
+
+func TestToolInputAccumulatorSurrogatePair(t *testing.T) {
+	var acc ToolInputAccumulator
+	acc.Feed(`{"emoji": "hi \ud83d`)
+	emoji, _ := acc.StringAt("emoji")
+	assert.Equal(t, "hi ", emoji)
+
+	acc.Feed(`\ude00`)
+	emoji, _ = acc.StringAt("emoji")
+	assert.Equal(t, "hi 😀", emoji)
+}
+
+func TestToolInputAccumulatorInvalid(t *testing.T) {
+	for _, input := range []string{
+		`{"a": 1,}`,
+		`{"a" 1}`,
+		`[1 2]`,
+		`{"a": trux}`,
+		`{"a": 01`,
+		`{"a": 1} extra`,
+		`nope`,
+	} {
+		var acc ToolInputAccumulator
+		acc.Feed(input)
+		assert.Equal(t, JSONInvalid, acc.State(), input)
+		assert.Nil(t, acc.Preview(), input)
+		_, err := acc.Value()
+		assert.EqualError(t, err, "anthropic: tool input is invalid JSON", input)
+	}
+}
+
+func TestToolInputAccumulatorEmpty(t *testing.T) {
+	var acc ToolInputAccumulator
+	value, err := acc.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(value))
+}
+
+func TestMessageStreamPartialJSON(t *testing.T) {
+	stream := newTestStream(t, thinkingToolTranscript)
+	var acc ToolInputAccumulator
+	for stream.Next() {
+		if event := stream.Event(); event.Type == StreamEventContentBlockDelta && event.ContentBlock.Type == "input_json_delta" {
+			acc.Feed(event.ContentBlock.PartialJSON)
+		}
+	}
+	assert.NoError(t, stream.Err())
+	value, err := acc.Value()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"city": "Paris"}`, string(value))
+}
@@ -0,0 +1,39 @@
+package anthropic
+
+import "context"
+
+const defaultMaxContinuations = 10
+
+// ContinuePausedTurn finishes a turn that msg, the response to params, paused
+// with StopReasonPauseTurn. The paused content is sent back as the assistant's
+// message so the model picks up where it left off, repeating for each further
+// pause up to maxContinuations times, 10 if it is not positive.
+//
+// The returned message holds the content of every leg in order, the sum of
+// their usage, and the stop reason of the last leg, which is still
+// StopReasonPauseTurn if the limit was reached. A msg that did not pause is
+// returned unchanged.
+func ContinuePausedTurn(ctx context.Context, svc MessageService, params MessageCreateParams, msg *Message, maxContinuations int, opts ...RequestOption) (*Message, error) {
+	if maxContinuations <= 0 {
+		maxContinuations = defaultMaxContinuations
+	}
+
+	history := params.Messages
+	turn := *msg
+	turn.Content = append([]ContentBlock(nil), msg.Content...)
+	for i := 0; i < maxContinuations && turn.StopReason == StopReasonPauseTurn; i++ {
+		params.Messages = append(history[:len(history):len(history)], turn.Param())
+		next, err := svc.CreateMessage(ctx, params, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		turn.ID = next.ID
+		turn.Model = next.Model
+		turn.Content = append(turn.Content, next.Content...)
+		turn.StopReason = next.StopReason
+		turn.StopSequence = next.StopSequence
+		turn.Usage.add(next.Usage)
+	}
+	return &turn, nil
+}
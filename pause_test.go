@@ -0,0 +1,86 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContinuePausedTurn(t *testing.T) {
+	responses := []string{
+		`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-7-sonnet-latest","stop_reason":"pause_turn","usage":{"input_tokens":10,"output_tokens":5},
+			"content":[{"type":"text","text":"Searching."},{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"go"}}]}`,
+		`{"id":"msg_2","type":"message","role":"assistant","model":"claude-3-7-sonnet-latest","stop_reason":"pause_turn","usage":{"input_tokens":20,"output_tokens":6},
+			"content":[{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","url":"https://go.dev"}]}]}`,
+		`{"id":"msg_3","type":"message","role":"assistant","model":"claude-3-7-sonnet-latest","stop_reason":"end_turn","usage":{"input_tokens":30,"output_tokens":7},
+			"content":[{"type":"text","text":" Go is at go.dev."}]}`,
+	}
+	var requests []map[string]json.RawMessage
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		io.WriteString(w, responses[len(requests)])
+		requests = append(requests, body)
+	})
+
+	params := MessageCreateParams{
+		Model:     ModelClaude3Haiku,
+		MaxTokens: 1024,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Where is Go?"}},
+	}
+	msg, err := client.CreateMessage(context.Background(), params)
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg, err = ContinuePausedTurn(context.Background(), client, params, msg, 0)
+	if !assert.NoError(t, err) || !assert.Len(t, requests, 3) {
+		return
+	}
+
+	assert.JSONEq(t, `[
+		{"role":"user","content":"Where is Go?"},
+		{"role":"assistant","content":[
+			{"type":"text","text":"Searching."},
+			{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"go"}}]}]`,
+		string(requests[1]["messages"]))
+	assert.JSONEq(t, `[
+		{"role":"user","content":"Where is Go?"},
+		{"role":"assistant","content":[
+			{"type":"text","text":"Searching."},
+			{"type":"server_tool_use","id":"srvtoolu_1","name":"web_search","input":{"query":"go"}},
+			{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","url":"https://go.dev"}]}]}]`,
+		string(requests[2]["messages"]))
+
+	assert.Equal(t, "msg_3", msg.ID)
+	assert.Equal(t, StopReasonEndTurn, msg.StopReason)
+	assert.Len(t, msg.Content, 4)
+	assert.Equal(t, "Searching. Go is at go.dev.", msg.Text())
+	assert.Equal(t, Usage{InputTokens: 60, OutputTokens: 18}, msg.Usage)
+	assert.Len(t, params.Messages, 1, "the caller's history is not modified")
+}
+
+func TestContinuePausedTurnLimit(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"type":"message","role":"assistant","stop_reason":"pause_turn","content":[{"type":"text","text":"."}]}`)
+	})
+
+	paused := &Message{Role: RoleAssistant, StopReason: StopReasonPauseTurn, Content: []ContentBlock{{Type: ContentBlockTypeText, Text: "."}}}
+	msg, err := ContinuePausedTurn(context.Background(), client, MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}, paused, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, StopReasonPauseTurn, msg.StopReason)
+	assert.Equal(t, "...", msg.Text())
+	assert.Len(t, paused.Content, 1)
+
+	done := &Message{StopReason: StopReasonEndTurn}
+	msg, err = ContinuePausedTurn(context.Background(), client, MessageCreateParams{}, done, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, done, msg)
+	assert.Equal(t, 2, calls)
+}
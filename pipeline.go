@@ -0,0 +1,63 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+)
+
+// Agent is a step in a Pipeline that turns an input into an output.
+// *Conversation and *Pipeline both implement it.
+type Agent interface {
+	Process(ctx context.Context, input string) (string, error)
+}
+
+// Router picks the index of the agent that receives output next. Any index
+// outside the pipeline's agents ends processing with output as the result.
+type Router func(ctx context.Context, output string) int
+
+const defaultPipelineMaxSteps = 100
+
+// Pipeline passes an input through a series of agents, feeding each agent's
+// output to the next. Without a Router agents run once each, in order.
+type Pipeline struct {
+	Agents []Agent
+	Router Router
+	// MaxSteps bounds how many agents may run for one input, guarding against
+	// routing cycles. Defaults to 100.
+	MaxSteps int
+}
+
+func NewPipeline(agents ...Agent) *Pipeline {
+	return &Pipeline{Agents: agents}
+}
+
+func (p *Pipeline) Process(ctx context.Context, input string) (string, error) {
+	maxSteps := p.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultPipelineMaxSteps
+	}
+
+	output := input
+	next := 0
+	for step := 0; next >= 0 && next < len(p.Agents); step++ {
+		if step >= maxSteps {
+			return output, fmt.Errorf("anthropic: pipeline exceeded %d steps", maxSteps)
+		}
+		if err := ctx.Err(); err != nil {
+			return output, err
+		}
+
+		var err error
+		output, err = p.Agents[next].Process(ctx, output)
+		if err != nil {
+			return output, fmt.Errorf("anthropic: pipeline agent %d: %w", next, err)
+		}
+
+		if p.Router != nil {
+			next = p.Router(ctx, output)
+		} else {
+			next++
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,72 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type agentFunc func(ctx context.Context, input string) (string, error)
+
+func (f agentFunc) Process(ctx context.Context, input string) (string, error) {
+	return f(ctx, input)
+}
+
+func TestPipelineSequential(t *testing.T) {
+	client := newTestClient(t, echoHandler(t))
+	p := NewPipeline(
+		NewConversation(client, ModelClaude3Haiku, "first", 100),
+		agentFunc(func(ctx context.Context, input string) (string, error) {
+			return strings.ToUpper(input), nil
+		}),
+		NewConversation(client, ModelClaude3Haiku, "second", 100),
+	)
+
+	out, err := p.Process(context.Background(), "hi")
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: ECHO: HI", out)
+}
+
+func TestPipelineRouter(t *testing.T) {
+	var visited []int
+	agent := func(i int) Agent {
+		return agentFunc(func(ctx context.Context, input string) (string, error) {
+			visited = append(visited, i)
+			return input + "+", nil
+		})
+	}
+
+	p := NewPipeline(agent(0), agent(1), agent(2))
+	p.Router = func(ctx context.Context, output string) int {
+		switch len(output) {
+		case 2:
+			return 2
+		case 3:
+			return 1
+		default:
+			return -1
+		}
+	}
+
+	out, err := p.Process(context.Background(), "x")
+	assert.NoError(t, err)
+	assert.Equal(t, "x+++", out)
+	assert.Equal(t, []int{0, 2, 1}, visited)
+
+	p.Router = func(ctx context.Context, output string) int { return 0 }
+	p.MaxSteps = 5
+	_, err = p.Process(context.Background(), "x")
+	assert.ErrorContains(t, err, "exceeded 5 steps")
+}
+
+func TestPipelineError(t *testing.T) {
+	boom := errors.New("boom")
+	p := NewPipeline(agentFunc(func(ctx context.Context, input string) (string, error) {
+		return "", boom
+	}))
+	_, err := p.Process(context.Background(), "x")
+	assert.ErrorIs(t, err, boom)
+}
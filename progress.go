@@ -0,0 +1,72 @@
+package anthropic
+
+import "time"
+
+// ProgressInfo describes how far a streamed message has got. See WithProgress.
+type ProgressInfo struct {
+	// Events is the number of events received so far.
+	Events int
+	// Usage is the token usage accumulated so far, as MessageStream.Usage
+	// reports it.
+	Usage Usage
+	// BlockIndex is the index of the content block being generated.
+	BlockIndex int
+	// Elapsed is the time since the request was sent.
+	Elapsed time.Duration
+	// SinceFirstToken is the time since the first content delta arrived, or
+	// zero if none has yet.
+	SinceFirstToken time.Duration
+	// Done reports that the message is complete.
+	Done bool
+}
+
+// WithProgress makes a streamed call report its progress to fn, for progress
+// bars and watchdogs. fn is called from Recv after each event, or at most once
+// per WithProgressInterval, and always for the final event.
+func WithProgress(fn func(ProgressInfo)) RequestOption {
+	return func(r *requestConfig) {
+		r.progress = fn
+	}
+}
+
+// WithProgressInterval sets the minimum time between calls to the
+// WithProgress function, debouncing it for fast streams.
+func WithProgressInterval(interval time.Duration) RequestOption {
+	return func(r *requestConfig) {
+		r.progressInterval = interval
+	}
+}
+
+// streamProgress holds the WithProgress function of a stream and when it was
+// last called. The progress itself comes from the stream's tracker.
+type streamProgress struct {
+	fn       func(ProgressInfo)
+	interval time.Duration
+	reported time.Time
+}
+
+// observeProgress reports progress to the WithProgress function, if it is due,
+// for an event that arrived at now.
+func (s *MessageStream) observeProgress(now time.Time) {
+	p := &s.progress
+	if p.fn == nil {
+		return
+	}
+	if !s.done && p.interval > 0 && !p.reported.IsZero() && now.Sub(p.reported) < p.interval {
+		return
+	}
+	p.reported = now
+
+	t := &s.tracker
+	info := ProgressInfo{
+		Events:     t.count,
+		Usage:      s.usage,
+		BlockIndex: t.blockIndex,
+		Elapsed:    now.Sub(t.started),
+		Done:       s.done,
+	}
+	if !t.firstToken.IsZero() {
+		info.SinceFirstToken = now.Sub(t.firstToken)
+	}
+	p.fn(info)
+}
@@ -0,0 +1,69 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProgress(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	}, WithClock(clock))
+
+	stream := func(opts ...RequestOption) []ProgressInfo {
+		var infos []ProgressInfo
+		opts = append(opts, WithProgress(func(info ProgressInfo) { infos = append(infos, info) }))
+		stream, err := client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}, opts...)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		defer stream.Close()
+		for {
+			// each event arrives 40ms after the last
+			clock.mu.Lock()
+			clock.now = clock.now.Add(40 * time.Millisecond)
+			clock.mu.Unlock()
+			if !stream.Next() {
+				break
+			}
+		}
+		assert.NoError(t, stream.Err())
+		return infos
+	}
+
+	infos := stream()
+	if !assert.Len(t, infos, 7) {
+		return
+	}
+	for i, info := range infos {
+		assert.Equal(t, i+1, info.Events)
+		assert.Equal(t, time.Duration(i+1)*40*time.Millisecond, info.Elapsed)
+		assert.Equal(t, i == 6, info.Done)
+	}
+	// the first text delta is the third event
+	assert.Zero(t, infos[1].SinceFirstToken)
+	assert.Zero(t, infos[2].SinceFirstToken)
+	assert.Equal(t, 40*time.Millisecond, infos[3].SinceFirstToken)
+	assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 1}, infos[0].Usage)
+	assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 7}, infos[6].Usage)
+
+	// debounced, with the final event always reported
+	infos = stream(WithProgressInterval(100 * time.Millisecond))
+	var events []int
+	var elapsed []time.Duration
+	for _, info := range infos {
+		events = append(events, info.Events)
+		elapsed = append(elapsed, info.Elapsed)
+	}
+	assert.Equal(t, []int{1, 4, 7}, events)
+	assert.Equal(t, []time.Duration{40 * time.Millisecond, 160 * time.Millisecond, 280 * time.Millisecond}, elapsed)
+	assert.True(t, infos[len(infos)-1].Done)
+	assert.Equal(t, 160*time.Millisecond, infos[len(infos)-1].SinceFirstToken)
+}
@@ -0,0 +1,117 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Events written by ProxyTo. They form a schema for browser clients that stays
+// the same whatever changes upstream.
+const (
+	ProxyEventMessageStart = "message_start"
+	ProxyEventText         = "text"
+	ProxyEventMessageStop  = "message_stop"
+	ProxyEventError        = "error"
+)
+
+type proxyMessageStart struct {
+	ID    string `json:"id"`
+	Model string `json:"model"`
+}
+
+type proxyText struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+type proxyMessageStop struct {
+	StopReason StopReason `json:"stop_reason"`
+	Usage      Usage      `json:"usage"`
+}
+
+type proxyError struct {
+	Message string `json:"message"`
+}
+
+// ProxyTo consumes the stream and re-emits it to w as server-sent events,
+// flushing after each one:
+//
+//	message_start  {"id", "model"}
+//	text           {"index", "text"}, for each text delta
+//	message_stop   {"stop_reason", "usage"}
+//	error          {"message"}, if the stream fails
+//
+// Other events are not forwarded. It returns the error that ended the stream
+// or failed a write, such as one caused by the client disconnecting.
+func (s *MessageStream) ProxyTo(w http.ResponseWriter) error {
+	return s.proxy(w)
+}
+
+// ProxyToRequest is like ProxyTo but also stops, closing the stream, when the
+// context of r is done because the client disconnected.
+func (s *MessageStream) ProxyToRequest(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	stop := context.AfterFunc(ctx, func() { s.Close() })
+	defer stop()
+
+	err := s.proxy(w)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (s *MessageStream) proxy(w http.ResponseWriter) error {
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// keeps nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	for {
+		event, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			// the error event is best effort, the client may already be gone
+			writeProxyEvent(w, rc, ProxyEventError, proxyError{Message: err.Error()})
+			return err
+		}
+
+		switch event.Type {
+		case StreamEventMessageStart:
+			if event.Message != nil {
+				err = writeProxyEvent(w, rc, ProxyEventMessageStart, proxyMessageStart{ID: event.Message.ID, Model: event.Message.Model})
+			}
+		case StreamEventContentBlockDelta:
+			if event.ContentBlock != nil && event.ContentBlock.Text != "" {
+				err = writeProxyEvent(w, rc, ProxyEventText, proxyText{Index: event.Index, Text: event.ContentBlock.Text})
+			}
+		case StreamEventMessageStop:
+			err = writeProxyEvent(w, rc, ProxyEventMessageStop, proxyMessageStop{StopReason: s.StopReason(), Usage: s.Usage()})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeProxyEvent writes one server-sent event and flushes it to the client.
+func writeProxyEvent(w io.Writer, rc *http.ResponseController, event string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
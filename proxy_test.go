@@ -0,0 +1,71 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageStreamProxyTo(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := newTestStream(t, testTranscript).ProxyTo(rec)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "event: message_start\n"+
+		"data: {\"id\":\"msg_01\",\"model\":\"claude-3-haiku-20240307\"}\n\n"+
+		"event: text\n"+
+		"data: {\"index\":0,\"text\":\"Hello\"}\n\n"+
+		"event: text\n"+
+		"data: {\"index\":0,\"text\":\", world\"}\n\n"+
+		"event: message_stop\n"+
+		"data: {\"stop_reason\":\"end_turn\",\"usage\":{\"input_tokens\":12,\"output_tokens\":7}}\n\n",
+		rec.Body.String())
+}
+
+func TestMessageStreamProxyToError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := newTestStream(t, "event: error\n"+
+		"data: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n").ProxyTo(rec)
+	assert.Error(t, err)
+
+	// the proxied event is valid SSE that the client can decode
+	event, decodeErr := newSSEDecoder(rec.Body).next()
+	assert.NoError(t, decodeErr)
+	assert.Equal(t, ProxyEventError, event.Type)
+	assert.JSONEq(t, `{"message":`+quoteJSON(t, err.Error())+`}`, string(event.Data))
+}
+
+func TestMessageStreamProxyToRequestDisconnect(t *testing.T) {
+	// the upstream stream stalls after its first event
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, "event: message_start\n"+
+			"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n")
+	}()
+	stream := StreamFromReader(pr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/chat", nil).WithContext(ctx)
+
+	done := make(chan error)
+	go func() { done <- stream.ProxyToRequest(rec, req) }()
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func quoteJSON(t *testing.T, s string) string {
+	t.Helper()
+	b, err := json.Marshal(s)
+	assert.NoError(t, err)
+	return string(b)
+}
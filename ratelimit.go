@@ -0,0 +1,43 @@
+package anthropic
+
+import (
+	"net/http"
+	"time"
+)
+
+// RateLimitEvent describes a request that was rate limited.
+type RateLimitEvent struct {
+	Time time.Time
+	// RetryAfter is how long the client waits before retrying, or zero if the
+	// request is not retried and the 429 is returned to the caller.
+	RetryAfter time.Duration
+	// AttemptNumber counts attempts at the request, starting at 1.
+	AttemptNumber int
+	// Model is the model the request was for, if any.
+	Model string
+}
+
+// WithRateLimitObserver registers fn to be called whenever a request receives
+// a 429 response. fn runs synchronously before the backoff sleep, so it
+// should return quickly.
+func WithRateLimitObserver(fn func(event RateLimitEvent)) ClientOption {
+	return func(c *Client) {
+		c.rateLimitObserver = fn
+	}
+}
+
+func (c *Client) observeRateLimit(req *http.Request, resp *http.Response, attempt int, retryAfter time.Duration) {
+	if c.rateLimitObserver == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	event := RateLimitEvent{
+		Time:          c.clock.Now(),
+		RetryAfter:    retryAfter,
+		AttemptNumber: attempt + 1,
+	}
+	if cfg := requestConfigFrom(req.Context()); cfg != nil {
+		event.Model = cfg.model
+	}
+	c.rateLimitObserver(event)
+}
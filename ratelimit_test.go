@@ -0,0 +1,54 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitObserver(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"Rate limited"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	})
+	client.minRetryDelay = time.Millisecond
+
+	var events []RateLimitEvent
+	client = client.WithOptions(WithRateLimitObserver(func(event RateLimitEvent) {
+		events = append(events, event)
+	}))
+
+	start := time.Now()
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.NoError(t, err)
+
+	if assert.Len(t, events, 2) {
+		for i, event := range events {
+			assert.Equal(t, i+1, event.AttemptNumber)
+			assert.Equal(t, ModelClaude3Haiku, event.Model)
+			assert.Equal(t, time.Millisecond<<i, event.RetryAfter)
+			assert.False(t, event.Time.Before(start))
+		}
+	}
+
+	// a 429 that is not retried is still reported
+	events = nil
+	statuses = []int{http.StatusTooManyRequests}
+	_, err = client.WithOptions(WithMaxRetries(0)).CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	assert.Error(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Zero(t, events[0].RetryAfter)
+		assert.Equal(t, 1, events[0].AttemptNumber)
+	}
+}
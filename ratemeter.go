@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultRateWindow is the window of the RateMeter returned by
+// MessageStream.RateMeter.
+const defaultRateWindow = 32
+
+// RateMeter measures the rate at which a stream generates answer text,
+// averaged over the last few deltas. Only text deltas are counted, each as
+// one token; thinking, signature and tool input deltas are not. A text delta
+// may carry more than one token, so the rate is an estimate that runs low,
+// not a count to bill against.
+//
+// A RateMeter is a StreamSink, so one with a different window can be attached
+// to a stream with StreamTee. It is safe to read from other goroutines while
+// the stream is being read.
+type RateMeter struct {
+	mu    sync.Mutex
+	clock Clock
+	times []time.Time
+	next  int
+	total int
+	peak  float64
+}
+
+// NewRateMeter returns a meter averaging over the last windowSize deltas,
+// which must be at least 2.
+func NewRateMeter(windowSize int) *RateMeter {
+	if windowSize < 2 {
+		windowSize = 2
+	}
+	return &RateMeter{clock: RealClock(), times: make([]time.Time, 0, windowSize)}
+}
+
+// WriteEvent records event if it is a text delta.
+func (m *RateMeter) WriteEvent(event *MessageStreamEvent) error {
+	if isTextDelta(event) {
+		m.record(m.clock.Now())
+	}
+	return nil
+}
+
+func isTextDelta(event *MessageStreamEvent) bool {
+	return event.Type == StreamEventContentBlockDelta && event.ContentBlock != nil && event.ContentBlock.Type == "text_delta"
+}
+
+func (m *RateMeter) record(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.times) < cap(m.times) {
+		m.times = append(m.times, t)
+	} else {
+		m.times[m.next] = t
+		m.next = (m.next + 1) % len(m.times)
+	}
+	m.total++
+	if rate := m.rate(); rate > m.peak {
+		m.peak = rate
+	}
+}
+
+// rate returns the average rate over the window, or 0 until two deltas have
+// arrived.
+func (m *RateMeter) rate() float64 {
+	if len(m.times) < 2 {
+		return 0
+	}
+	oldest := m.times[m.next]
+	newest := m.times[(m.next+len(m.times)-1)%len(m.times)]
+	elapsed := newest.Sub(oldest).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(len(m.times)-1) / elapsed
+}
+
+// TokensPerSecond returns the current rate, averaged over the window.
+func (m *RateMeter) TokensPerSecond() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rate()
+}
+
+// Peak returns the highest rate seen so far.
+func (m *RateMeter) Peak() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.peak
+}
+
+// Total returns the number of text deltas received so far.
+func (m *RateMeter) Total() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+// RateMeter returns the meter measuring the stream's generation rate over its
+// last 32 text deltas, creating it on first use: it sees only the events read
+// after that, so call it before reading the stream. Use NewRateMeter and
+// StreamTee for a different window.
+func (s *MessageStream) RateMeter() *RateMeter {
+	if s.meter == nil {
+		s.meter = NewRateMeter(defaultRateWindow)
+		s.meter.clock = s.tracker.clock
+	}
+	return s.meter
+}
@@ -0,0 +1,100 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateMeter(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := NewRateMeter(3)
+	delta := &MessageStreamEvent{Type: StreamEventContentBlockDelta, ContentBlock: &ContentBlock{Type: "text_delta", Text: "Hi"}}
+	at := func(offset time.Duration) {
+		m.record(start.Add(offset))
+	}
+
+	assert.Zero(t, m.TokensPerSecond())
+	at(0)
+	assert.Zero(t, m.TokensPerSecond(), "a rate needs two deltas")
+	at(100 * time.Millisecond)
+	assert.InDelta(t, 10, m.TokensPerSecond(), 1e-9)
+	at(150 * time.Millisecond)
+	assert.InDelta(t, 2/0.15, m.TokensPerSecond(), 1e-9)
+
+	// the window drops the oldest delta
+	at(250 * time.Millisecond)
+	assert.InDelta(t, 2/0.15, m.TokensPerSecond(), 1e-9)
+	at(1250 * time.Millisecond)
+	assert.InDelta(t, 2/1.1, m.TokensPerSecond(), 1e-9)
+
+	assert.InDelta(t, 2/0.15, m.Peak(), 1e-9)
+	assert.Equal(t, 5, m.Total())
+
+	// only text deltas count
+	assert.NoError(t, m.WriteEvent(&MessageStreamEvent{Type: StreamEventMessageDelta}))
+	for _, deltaType := range []ContentBlockType{"thinking_delta", "signature_delta", "input_json_delta"} {
+		assert.NoError(t, m.WriteEvent(&MessageStreamEvent{Type: StreamEventContentBlockDelta, ContentBlock: &ContentBlock{Type: deltaType}}))
+	}
+	assert.Equal(t, 5, m.Total())
+	assert.NoError(t, m.WriteEvent(delta))
+	assert.Equal(t, 6, m.Total())
+}
+
+func TestMessageStreamRateMeter(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	}, WithClock(clock))
+
+	stream, err := client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	meter := stream.RateMeter()
+	assert.Same(t, meter, stream.RateMeter())
+	wide := NewRateMeter(10)
+	wide.clock = clock
+	StreamTee(stream, wide)
+	for {
+		// each event arrives 50ms after the last
+		clock.mu.Lock()
+		clock.now = clock.now.Add(50 * time.Millisecond)
+		clock.mu.Unlock()
+		if !stream.Next() {
+			break
+		}
+	}
+	assert.NoError(t, stream.Err())
+
+	// the transcript's two deltas arrive 50ms apart
+	assert.Equal(t, 2, meter.Total())
+	assert.InDelta(t, 20, meter.TokensPerSecond(), 1e-9)
+	assert.InDelta(t, 20, meter.Peak(), 1e-9)
+	assert.Equal(t, 2, wide.Total())
+	assert.InDelta(t, 20, wide.TokensPerSecond(), 1e-9)
+}
+
+func TestMessageStreamRateMeterLazy(t *testing.T) {
+	// a stream nobody asks for a meter keeps none
+	stream := newTestStream(t, thinkingToolTranscript)
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Nil(t, stream.meter)
+
+	// thinking, signature and tool input deltas aren't counted
+	stream = newTestStream(t, thinkingToolTranscript)
+	meter := stream.RateMeter()
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, 2, meter.Total())
+}
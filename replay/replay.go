@@ -0,0 +1,190 @@
+// Package replay records Anthropic API exchanges to disk and plays them back
+// without network access, for debugging prompts and reproducing issues.
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	anthropic "github.com/gage-technologies/anthropic-go"
+)
+
+type recording struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// RecordingClient is an *anthropic.Client whose requests are passed through to
+// the API and saved to disk.
+type RecordingClient struct {
+	*anthropic.Client
+}
+
+// NewRecorder wraps client so that every request and its response is written
+// to dir, one file per request named by a hash of the request.
+func NewRecorder(client *anthropic.Client, dir string) *RecordingClient {
+	return &RecordingClient{
+		Client: client.WithOptions(anthropic.WithRoundTripper(func(next http.RoundTripper) http.RoundTripper {
+			return &recorder{dir: dir, next: next}
+		})),
+	}
+}
+
+// ReplayingClient is an *anthropic.Client that serves every request from
+// recordings on disk.
+type ReplayingClient struct {
+	*anthropic.Client
+}
+
+// NewReplayer returns a client that answers requests from the recordings in
+// dir. Requests without a recording fail.
+func NewReplayer(dir string) *ReplayingClient {
+	return &ReplayingClient{
+		Client: anthropic.NewClient(
+			anthropic.WithAPIKey("replay"),
+			anthropic.WithHTTPClient(&http.Client{Transport: &replayer{dir: dir}}),
+		),
+	}
+}
+
+type recorder struct {
+	dir  string
+	next http.RoundTripper
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recordedBody := json.RawMessage(body)
+	if len(body) > 0 && !json.Valid(body) {
+		recordedBody, _ = json.Marshal(string(body))
+	}
+	rec := recording{
+		Request: recordedRequest{Method: req.Method, Path: req.URL.Path, Body: recordedBody},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+		},
+	}
+	// the body is saved once the caller is done with it so streams are still
+	// delivered incrementally
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		path:       filepath.Join(r.dir, requestKey(req.Method, req.URL.Path, body)+".json"),
+		rec:        rec,
+	}
+	return resp, nil
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	path string
+	rec  recording
+	buf  bytes.Buffer
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	// drain whatever the caller didn't read so the recording is complete
+	io.Copy(&b.buf, b.ReadCloser)
+	closeErr := b.ReadCloser.Close()
+
+	b.rec.Response.Body = b.buf.String()
+	data, err := json.MarshalIndent(b.rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(b.path, data, 0o644); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+type replayer struct {
+	dir string
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := requestKey(req.Method, req.URL.Path, body)
+	data, err := os.ReadFile(filepath.Join(r.dir, key+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("replay: no recording for %s %s (%s): %w", req.Method, req.URL.Path, key, err)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("replay: reading recording %s: %w", key, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Response.StatusCode, http.StatusText(rec.Response.StatusCode)),
+		StatusCode:    rec.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Response.Header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Response.Body))),
+		ContentLength: int64(len(rec.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func requestKey(method, path string, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, path)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,88 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	anthropic "github.com/gage-technologies/anthropic-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const transcript = "event: message_start\n" +
+	"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_02\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Streamed\"}}\n\n" +
+	"event: message_delta\n" +
+	"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":1}}\n\n"
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, transcript)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Recorded"}],"model":"claude-3-haiku-20240307"}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	params := anthropic.MessageCreateParams{
+		Model:     anthropic.ModelClaude3Haiku,
+		MaxTokens: 10,
+		Messages:  []anthropic.MessageParam{{Role: anthropic.RoleUser, Content: "Hi"}},
+	}
+
+	recorder := NewRecorder(anthropic.NewClient(anthropic.WithAPIKey("test"), anthropic.WithBaseURL(server.URL)), dir)
+	msg, err := recorder.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "Recorded", msg.Content[0].Text)
+	assert.Equal(t, "Streamed", streamText(t, recorder.Client, params))
+	assert.Equal(t, 2, calls)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	replayer := NewReplayer(dir)
+	replayed, err := replayer.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, replayed)
+	assert.Equal(t, "Streamed", streamText(t, replayer.Client, params))
+	assert.Equal(t, 2, calls)
+
+	params.Messages[0].Content = "Something new"
+	_, err = replayer.CreateMessage(context.Background(), params)
+	assert.Error(t, err)
+}
+
+func streamText(t *testing.T, client *anthropic.Client, params anthropic.MessageCreateParams) string {
+	stream, err := client.StreamMessage(context.Background(), params)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer stream.Close()
+
+	text := ""
+	for {
+		m, err := stream.Recv()
+		if errors.Is(err, io.EOF) || !assert.NoError(t, err) {
+			break
+		}
+		if m.Delta != nil && m.Delta.StopReason != "" {
+			break
+		}
+		if m.Type == anthropic.StreamEventContentBlockDelta {
+			text += m.ContentBlock.Text
+		}
+	}
+	return text
+}
@@ -0,0 +1,131 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+)
+
+// ResilientMessageStream is a MessageStream that re-establishes itself when the
+// connection drops mid-stream. See StreamMessageResilient.
+type ResilientMessageStream struct {
+	client  *Client
+	ctx     context.Context
+	params  MessageCreateParams
+	opts    []RequestOption
+	stream  *MessageStream
+	text    strings.Builder
+	retries int
+	resumed bool
+	// open holds the indexes of the content blocks started but not stopped
+	open map[int]bool
+}
+
+// StreamMessageResilient behaves like StreamMessage but reconnects, up to the
+// client's max retries, when the connection drops before the message
+// completes.
+//
+// Resumption is best-effort and happens at the turn level: the text received
+// so far is sent back as an assistant prefill and the model continues from
+// there. Exact token-level resumption is not possible, so the continuation may
+// differ from what the original stream would have produced. Trailing
+// whitespace is trimmed from the prefill as the API rejects it.
+func (c *Client) StreamMessageResilient(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*ResilientMessageStream, error) {
+	stream, err := c.StreamMessage(ctx, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResilientMessageStream{
+		client: c,
+		ctx:    ctx,
+		params: params,
+		opts:   opts,
+		stream: stream,
+		open:   make(map[int]bool),
+	}, nil
+}
+
+func (s *ResilientMessageStream) Close() error {
+	return s.stream.Close()
+}
+
+func (s *ResilientMessageStream) Recv() (*MessageStreamEvent, error) {
+	for {
+		event, err := s.stream.Recv()
+		if err == nil {
+			// the reconnected stream starts a new message, and starts again the
+			// block it continues; keep presenting a single one of each to the
+			// caller
+			switch event.Type {
+			case StreamEventMessageStart:
+				if s.resumed {
+					continue
+				}
+			case StreamEventContentBlockStart:
+				if s.open[event.Index] {
+					continue
+				}
+				s.open[event.Index] = true
+			case StreamEventContentBlockStop:
+				delete(s.open, event.Index)
+			}
+			if event.Type == StreamEventContentBlockDelta && event.ContentBlock != nil {
+				s.text.WriteString(event.ContentBlock.Text)
+			}
+			return event, nil
+		}
+
+		if !s.shouldResume(err) {
+			return nil, err
+		}
+		s.retries++
+		s.stream.Close()
+
+		// the server's retry field sets the minimum wait before reconnecting
+		if delay := s.stream.ReconnectDelay(); delay > 0 {
+			if err := s.client.sleep(s.ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		stream, err := s.client.StreamMessage(s.ctx, s.resumeParams(), s.opts...)
+		if err != nil {
+			return nil, err
+		}
+		s.stream = stream
+		s.resumed = true
+	}
+}
+
+func (s *ResilientMessageStream) shouldResume(err error) bool {
+	if s.retries >= s.client.maxRetries || s.ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (s *ResilientMessageStream) resumeParams() MessageCreateParams {
+	params := s.params
+	prefill := strings.TrimRight(s.text.String(), " \t\r\n")
+	if prefill == "" {
+		return params
+	}
+
+	params.Messages = make([]MessageParam, len(s.params.Messages), len(s.params.Messages)+1)
+	copy(params.Messages, s.params.Messages)
+
+	last := len(params.Messages) - 1
+	if last >= 0 && params.Messages[last].Role == RoleAssistant {
+		params.Messages[last].Content += prefill
+	} else {
+		params.Messages = append(params.Messages, MessageParam{Role: RoleAssistant, Content: prefill})
+	}
+	return params
+}
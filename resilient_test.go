@@ -0,0 +1,85 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMessageResilient(t *testing.T) {
+	attempts := 0
+	clock := &fakeClock{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+
+		flusher := w.(http.Flusher)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n")
+		fmt.Fprint(w, "event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+
+		if attempts == 1 {
+			assert.Len(t, params.Messages, 1)
+			fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"The quick brown\"}}\n\n")
+			fmt.Fprint(w, "retry: 2500\n\n")
+			flusher.Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			conn.Close()
+			return
+		}
+
+		assert.Len(t, params.Messages, 2)
+		assert.Equal(t, MessageParam{Role: RoleAssistant, Content: "The quick brown"}, params.Messages[1])
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\" fox\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":4}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}, WithClock(clock))
+
+	stream, err := client.StreamMessageResilient(context.Background(), MessageCreateParams{
+		Model:     ModelClaude3Haiku,
+		MaxTokens: 100,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Say the phrase"}},
+	})
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	content := ""
+	var events []StreamEvent
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			break
+		}
+		events = append(events, m.Type)
+		if m.Type == StreamEventContentBlockDelta {
+			content += m.ContentBlock.Text
+		}
+	}
+
+	assert.Equal(t, 2, attempts)
+	// the caller sees one message and one block, as if the stream never broke
+	assert.Equal(t, []StreamEvent{
+		StreamEventMessageStart,
+		StreamEventContentBlockStart,
+		StreamEventContentBlockDelta,
+		StreamEventContentBlockDelta,
+		StreamEventContentBlockStop,
+		StreamEventMessageDelta,
+		StreamEventMessageStop,
+	}, events)
+	assert.Equal(t, "The quick brown fox", content)
+	// the reconnect waited as long as the retry field asked
+	assert.Equal(t, []time.Duration{2500 * time.Millisecond}, clock.sleeps)
+}
@@ -0,0 +1,47 @@
+package anthropic
+
+import "net/http"
+
+// ResponseMeta describes the HTTP response to a call. Pass a pointer to one
+// with WithResponseMeta to have it filled in once the response arrives,
+// whether or not the call succeeds.
+type ResponseMeta struct {
+	StatusCode    int
+	RequestID     string
+	Header        http.Header
+	ContentType   string
+	ContentLength int64
+
+	// OrganizationID and WorkspaceID identify the organization and workspace
+	// that served the call, from the anthropic-organization-id and
+	// anthropic-workspace-id headers. Either is empty if its header is absent.
+	OrganizationID string
+	WorkspaceID    string
+
+	// FallbackOccurred reports that CreateMessage fell back from
+	// RequestedModel to another model set with WithModelFallback.
+	FallbackOccurred bool
+	RequestedModel   string
+}
+
+// WithResponseMeta fills meta with details of the response to this call.
+func WithResponseMeta(meta *ResponseMeta) RequestOption {
+	return func(r *requestConfig) {
+		r.responseMeta = meta
+	}
+}
+
+// responseMetaFrom returns the ResponseMeta passed in opts, if any.
+func responseMetaFrom(opts []RequestOption) *ResponseMeta {
+	return optionsConfig(opts).responseMeta
+}
+
+func (m *ResponseMeta) fill(resp *http.Response) {
+	m.StatusCode = resp.StatusCode
+	m.RequestID = resp.Header.Get("request-id")
+	m.Header = resp.Header
+	m.ContentType = resp.Header.Get("Content-Type")
+	m.ContentLength = resp.ContentLength
+	m.OrganizationID = resp.Header.Get("anthropic-organization-id")
+	m.WorkspaceID = resp.Header.Get("anthropic-workspace-id")
+}
@@ -0,0 +1,38 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseMetaOrganization(t *testing.T) {
+	withHeaders := true
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_1")
+		if withHeaders {
+			w.Header().Set("anthropic-organization-id", "org_123")
+			w.Header().Set("anthropic-workspace-id", "wrkspc_456")
+		}
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","content":[]}`)
+	})
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	var meta ResponseMeta
+	_, err := client.CreateMessage(context.Background(), params, WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, "req_1", meta.RequestID)
+	assert.Equal(t, "org_123", meta.OrganizationID)
+	assert.Equal(t, "wrkspc_456", meta.WorkspaceID)
+
+	withHeaders = false
+	meta = ResponseMeta{}
+	_, err = client.CreateMessage(context.Background(), params, WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, "req_1", meta.RequestID)
+	assert.Empty(t, meta.OrganizationID)
+	assert.Empty(t, meta.WorkspaceID)
+}
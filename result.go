@@ -0,0 +1,74 @@
+package anthropic
+
+import "encoding/json"
+
+// CompletionResult wraps a Message with accessors for the common cases, so
+// callers don't have to walk its content blocks.
+type CompletionResult struct {
+	msg *Message
+}
+
+// ToolUseBlock is a tool call requested by the model.
+type ToolUseBlock struct {
+	ID    string
+	Name  string
+	Input json.RawMessage
+}
+
+func NewCompletionResult(msg *Message) *CompletionResult {
+	return &CompletionResult{msg: msg}
+}
+
+// Message returns the wrapped message.
+func (r *CompletionResult) Message() *Message {
+	return r.msg
+}
+
+// Text returns the first text block, or "" if there is none. Use
+// Message().Text() for all of them joined.
+func (r *CompletionResult) Text() string {
+	for _, block := range r.msg.Content {
+		if block.Type == ContentBlockTypeText {
+			return block.Text
+		}
+	}
+	return ""
+}
+
+// AllText returns every text block in order.
+func (r *CompletionResult) AllText() []string {
+	var text []string
+	for _, block := range r.msg.Content {
+		if block.Type == ContentBlockTypeText {
+			text = append(text, block.Text)
+		}
+	}
+	return text
+}
+
+// ToolUseCalls returns the tool calls the model made, in order.
+func (r *CompletionResult) ToolUseCalls() []ToolUseBlock {
+	var calls []ToolUseBlock
+	for _, block := range r.msg.Content {
+		if block.Type == ContentBlockTypeToolUse {
+			calls = append(calls, ToolUseBlock{ID: block.ID, Name: block.Name, Input: block.Input})
+		}
+	}
+	return calls
+}
+
+func (r *CompletionResult) StopReason() StopReason {
+	return r.msg.StopReason
+}
+
+func (r *CompletionResult) InputTokens() int {
+	return r.msg.Usage.InputTokens
+}
+
+func (r *CompletionResult) OutputTokens() int {
+	return r.msg.Usage.OutputTokens
+}
+
+func (r *CompletionResult) TotalTokens() int {
+	return r.msg.Usage.InputTokens + r.msg.Usage.OutputTokens
+}
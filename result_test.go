@@ -0,0 +1,46 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionResult(t *testing.T) {
+	var msg Message
+	err := json.Unmarshal([]byte(`{
+		"id":"msg_01","type":"message","role":"assistant","model":"claude-3-5-sonnet-20240620",
+		"content":[
+			{"type":"text","text":"Let me check the weather."},
+			{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{"location":"Paris"}},
+			{"type":"text","text":"And the time."},
+			{"type":"tool_use","id":"toolu_02","name":"get_time","input":{}}
+		],
+		"stop_reason":"tool_use",
+		"usage":{"input_tokens":120,"output_tokens":45}
+	}`), &msg)
+	assert.NoError(t, err)
+
+	result := NewCompletionResult(&msg)
+	assert.Same(t, &msg, result.Message())
+	assert.Equal(t, "Let me check the weather.", result.Text())
+	assert.Equal(t, []string{"Let me check the weather.", "And the time."}, result.AllText())
+	assert.Equal(t, StopReasonToolUse, result.StopReason())
+	assert.Equal(t, 120, result.InputTokens())
+	assert.Equal(t, 45, result.OutputTokens())
+	assert.Equal(t, 165, result.TotalTokens())
+
+	calls := result.ToolUseCalls()
+	if assert.Len(t, calls, 2) {
+		assert.Equal(t, "toolu_01", calls[0].ID)
+		assert.Equal(t, "get_weather", calls[0].Name)
+		assert.JSONEq(t, `{"location":"Paris"}`, string(calls[0].Input))
+		assert.Equal(t, "get_time", calls[1].Name)
+	}
+
+	empty := NewCompletionResult(&Message{})
+	assert.Empty(t, empty.Text())
+	assert.Nil(t, empty.AllText())
+	assert.Nil(t, empty.ToolUseCalls())
+}
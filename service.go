@@ -0,0 +1,13 @@
+package anthropic
+
+import "context"
+
+// MessageService is the part of *Client that creates messages. Code that
+// depends on it rather than on *Client can be tested with a fake.
+type MessageService interface {
+	CreateMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*Message, error)
+	StreamMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*MessageStream, error)
+	CountTokens(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*TokenCount, error)
+}
+
+var _ MessageService = (*Client)(nil)
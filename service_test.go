@@ -0,0 +1,79 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ MessageService = (*fakeMessageService)(nil)
+
+// fakeMessageService answers every message with reply.
+type fakeMessageService struct {
+	reply string
+	calls []MessageCreateParams
+}
+
+func (f *fakeMessageService) CreateMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*Message, error) {
+	f.calls = append(f.calls, params)
+	return &Message{Role: "assistant", Content: []ContentBlock{{Type: "text", Text: f.reply}}, StopReason: StopReasonEndTurn}, nil
+}
+
+func (f *fakeMessageService) StreamMessage(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*MessageStream, error) {
+	f.calls = append(f.calls, params)
+	return StreamFromReader(io.NopCloser(strings.NewReader(testTranscript))), nil
+}
+
+func (f *fakeMessageService) CountTokens(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*TokenCount, error) {
+	return &TokenCount{InputTokens: len(params.Messages)}, nil
+}
+
+func TestMessageServiceFake(t *testing.T) {
+	// ask stands in for downstream code that depends on the interface
+	ask := func(svc MessageService, question string) (string, error) {
+		msg, err := svc.CreateMessage(context.Background(), MessageCreateParams{
+			Model:     ModelClaude3Haiku,
+			MaxTokens: 100,
+			Messages:  []MessageParam{{Role: "user", Content: question}},
+		})
+		if err != nil {
+			return "", err
+		}
+		return msg.Text(), nil
+	}
+
+	fake := &fakeMessageService{reply: "42"}
+	answer, err := ask(fake, "What is the answer?")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", answer)
+	if assert.Len(t, fake.calls, 1) {
+		assert.Equal(t, "What is the answer?", fake.calls[0].Messages[0].Content)
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages/count_tokens", r.URL.Path)
+		var body map[string]json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `"`+ModelClaude3Haiku+`"`, string(body["model"]))
+		assert.NotContains(t, body, "max_tokens")
+		assert.NotContains(t, body, "temperature")
+		io.WriteString(w, `{"input_tokens": 14}`)
+	})
+
+	count, err := client.CountTokens(context.Background(), MessageCreateParams{
+		Model:       ModelClaude3Haiku,
+		MaxTokens:   100,
+		Temperature: 0.5,
+		Messages:    []MessageParam{{Role: "user", Content: "Hello"}},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 14, count.InputTokens)
+	}
+}
@@ -0,0 +1,77 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// WithSingleFlight deduplicates concurrent identical CreateMessage calls: while
+// one is in flight, the others wait for and share its result instead of
+// calling the API. Requests are identical when their parameters serialize
+// identically once client defaults are applied. Sampling is not taken into
+// account, so callers sharing a call get the same answer rather than
+// independent samples; enable it only where that is what you want.
+//
+// Calls with request options, streamed messages and every call of a client
+// whose credentials come from WithAPIKeyProvider, WithAPIKeyFunc or
+// WithAuthTokenFunc, which may differ from caller to caller, are never
+// shared. The shared call runs with the first caller's context values but is
+// not canceled when a waiting caller's context is done; that caller alone
+// returns early.
+func WithSingleFlight() ClientOption {
+	return func(c *Client) {
+		c.singleFlight = &singleflight.Group{}
+	}
+}
+
+// createMessageShared runs createMessageFallback for params, joining an
+// identical call already in flight.
+func (c *Client) createMessageShared(ctx context.Context, params MessageCreateParams) (*Message, error) {
+	key, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// no single caller owns the shared call, so none of them can cancel it
+	shared := context.WithoutCancel(ctx)
+	ch := c.singleFlight.DoChan(string(key), func() (interface{}, error) {
+		return c.createMessageFallback(shared, params)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// each caller gets its own copy to modify
+		return cloneMessage(res.Val.(*Message)), nil
+	}
+}
+
+// canShare reports whether a CreateMessage call for params may join an
+// identical call made by another caller.
+func (c *Client) canShare(params MessageCreateParams, opts []RequestOption) bool {
+	return c.singleFlight != nil && len(opts) == 0 && c.keyProvider == nil && c.tokenProvider == nil
+}
+
+// cloneMessage returns a copy of msg that shares no memory with it.
+func cloneMessage(msg *Message) *Message {
+	clone := *msg
+	clone.Content = make([]ContentBlock, len(msg.Content))
+	for i, block := range msg.Content {
+		block.Input = cloneRaw(block.Input)
+		block.Content = cloneRaw(block.Content)
+		clone.Content[i] = block
+	}
+	return &clone
+}
+
+func cloneRaw(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	return append(json.RawMessage(nil), raw...)
+}
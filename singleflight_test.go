@@ -0,0 +1,129 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		arrived <- struct{}{}
+		<-release
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Ok"},{"type":"tool_use","id":"toolu_01","name":"f","input":{"a":1}}]}`))
+	}, WithSingleFlight())
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+
+	const n = 10
+	var started, done sync.WaitGroup
+	messages := make([]*Message, n)
+	errs := make([]error, n)
+	started.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			messages[i], errs[i] = client.CreateMessage(context.Background(), params)
+		}(i)
+	}
+
+	<-arrived
+	started.Wait()
+	// give the callers that haven't joined the in-flight call time to do so
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for i := 0; i < n; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, "Ok", messages[i].Content[0].Text)
+	}
+	messages[0].Content[0].Text = "changed"
+	messages[0].Content[1].Input[5] = '2'
+	assert.Equal(t, "Ok", messages[1].Content[0].Text)
+	assert.JSONEq(t, `{"a":1}`, string(messages[1].Content[1].Input))
+}
+
+// overlappingCalls makes two CreateMessage calls for params at once and
+// returns how many requests reached the server.
+func overlappingCalls(t *testing.T, params MessageCreateParams, opts ...ClientOption) int32 {
+	t.Helper()
+	var calls atomic.Int32
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		arrived <- struct{}{}
+		<-release
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}, append([]ClientOption{WithSingleFlight()}, opts...)...)
+
+	var done sync.WaitGroup
+	done.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer done.Done()
+			_, err := client.CreateMessage(context.Background(), params)
+			assert.NoError(t, err)
+		}()
+	}
+	<-arrived
+	// give the second call time to join the first, or reach the server
+	select {
+	case <-arrived:
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	done.Wait()
+	return calls.Load()
+}
+
+func TestWithSingleFlightSampled(t *testing.T) {
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+	assert.Equal(t, int32(1), overlappingCalls(t, params))
+
+	// a zero Temperature is left out, so it can't mark a request deterministic;
+	// sampled requests are shared alike
+	sampled := params
+	sampled.Temperature = 0.7
+	assert.Equal(t, int32(1), overlappingCalls(t, sampled))
+}
+
+func TestWithSingleFlightCredentialFunc(t *testing.T) {
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+	tenantKey := func(ctx context.Context) (string, error) { return "tenant-key", nil }
+	assert.Equal(t, int32(2), overlappingCalls(t, params, WithAPIKeyFunc(tenantKey)))
+	assert.Equal(t, int32(2), overlappingCalls(t, params, WithAuthTokenFunc(tenantKey)))
+}
+
+func TestWithSingleFlightNotShared(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[]}`))
+	}, WithSingleFlight())
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: RoleUser, Content: "Hi"}}}
+
+	// calls that don't overlap are each sent
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	var meta ResponseMeta
+	_, err = client.CreateMessage(context.Background(), params, WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
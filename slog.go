@@ -0,0 +1,63 @@
+package anthropic
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WithSlogLogger logs every API request to logger once its response arrives,
+// at Debug level if it succeeded and at Error level if it failed. Records carry
+// the attributes anthropic.model, anthropic.input_tokens,
+// anthropic.output_tokens, anthropic.request_id, anthropic.duration_ms and
+// http.status_code, each when known. Streamed requests are logged when the
+// stream opens, so they have no token counts.
+func WithSlogLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.slogger = logger
+	}
+}
+
+// logRequest logs req, which got resp and err after starting at started, and
+// whose response body was decoded into v.
+func (c *Client) logRequest(req *http.Request, resp *http.Response, v interface{}, err error, started time.Time) {
+	if c.slogger == nil {
+		return
+	}
+
+	var attrs []slog.Attr
+	if cfg := requestConfigFrom(req.Context()); cfg != nil && cfg.model != "" {
+		attrs = append(attrs, slog.String("anthropic.model", cfg.model))
+	}
+	if err == nil {
+		switch v := v.(type) {
+		case *Message:
+			attrs = append(attrs,
+				slog.Int("anthropic.input_tokens", v.Usage.InputTokens),
+				slog.Int("anthropic.output_tokens", v.Usage.OutputTokens))
+		case *TokenCount:
+			attrs = append(attrs, slog.Int("anthropic.input_tokens", v.InputTokens))
+		}
+	}
+
+	var apiErr *APIError
+	switch {
+	case resp != nil:
+		attrs = append(attrs,
+			slog.String("anthropic.request_id", resp.Header.Get("request-id")),
+			slog.Int("http.status_code", resp.StatusCode))
+	case errors.As(err, &apiErr):
+		attrs = append(attrs,
+			slog.String("anthropic.request_id", apiErr.RequestID),
+			slog.Int("http.status_code", apiErr.StatusCode))
+	}
+	attrs = append(attrs, slog.Int64("anthropic.duration_ms", c.clock.Now().Sub(started).Milliseconds()))
+
+	level, msg := slog.LevelDebug, "anthropic: request"
+	if err != nil {
+		level, msg = slog.LevelError, "anthropic: request failed"
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.slogger.LogAttrs(req.Context(), level, msg, attrs...)
+}
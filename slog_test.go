@@ -0,0 +1,58 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	fail := false
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		clock.mu.Lock()
+		clock.now = clock.now.Add(1500 * time.Millisecond)
+		clock.mu.Unlock()
+		w.Header().Set("request-id", "req_123")
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`)
+			return
+		}
+		io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Hi"}],"usage":{"input_tokens":12,"output_tokens":3}}`)
+	}, WithClock(clock), WithSlogLogger(logger), WithMaxRetries(0))
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10, Messages: []MessageParam{{Role: "user", Content: "Hello"}}}
+
+	_, err := client.CreateMessage(context.Background(), params)
+	assert.NoError(t, err)
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, ModelClaude3Haiku, record["anthropic.model"])
+	assert.EqualValues(t, 12, record["anthropic.input_tokens"])
+	assert.EqualValues(t, 3, record["anthropic.output_tokens"])
+	assert.Equal(t, "req_123", record["anthropic.request_id"])
+	assert.EqualValues(t, 1500, record["anthropic.duration_ms"])
+	assert.EqualValues(t, http.StatusOK, record["http.status_code"])
+
+	buf.Reset()
+	fail = true
+	_, err = client.CreateMessage(context.Background(), params)
+	assert.Error(t, err)
+	record = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "req_123", record["anthropic.request_id"])
+	assert.EqualValues(t, http.StatusBadRequest, record["http.status_code"])
+	assert.NotContains(t, record, "anthropic.input_tokens")
+	assert.Contains(t, record["error"], "invalid_request_error")
+}
@@ -0,0 +1,261 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseEvent is a single server-sent event.
+type sseEvent struct {
+	Type string
+	// Data may share the decoder's buffer, so it is only valid until the
+	// decoder's next call.
+	Data []byte
+}
+
+// eventDecoder splits a streamed response body into events.
+type eventDecoder interface {
+	next() (sseEvent, error)
+}
+
+// sseDecoder splits a text/event-stream body into events.
+type sseDecoder struct {
+	reader  *bufio.Reader
+	started bool
+	// retry is the reconnection delay last set by a retry field.
+	retry time.Duration
+	// line and data are reused from event to event, so decoding a long
+	// stream doesn't allocate for each of its lines.
+	line []byte
+	data []byte
+	// afterCR reports that the last line ended with a "\r" that may be
+	// followed by a "\n".
+	afterCR bool
+}
+
+func newSSEDecoder(r io.Reader) *sseDecoder {
+	return &sseDecoder{reader: bufio.NewReader(r)}
+}
+
+// next returns the next event in the stream, or io.EOF once the stream is
+// exhausted. Blocks with neither an event nor a data field, such as one that
+// only sets retry, are not events and are skipped.
+func (d *sseDecoder) next() (sseEvent, error) {
+	for {
+		event, dispatch, err := d.readBlock()
+		if err != nil || dispatch {
+			return event, err
+		}
+	}
+}
+
+var (
+	sseFieldSeparator = []byte(": ")
+	byteOrderMark     = []byte("\ufeff")
+)
+
+// readBlock reads the fields up to the next blank line, reporting whether they
+// make up an event.
+func (d *sseDecoder) readBlock() (sseEvent, bool, error) {
+	var event sseEvent
+	d.data = d.data[:0]
+	hasData := false
+	empty := true
+	dispatch := false
+
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return sseEvent{}, false, err
+		}
+
+		// some proxies prepend a UTF-8 byte order mark to the stream
+		if !d.started {
+			d.started = true
+			line = bytes.TrimPrefix(line, byteOrderMark)
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if empty {
+				continue
+			}
+			break
+		}
+		empty = false
+
+		field, value, ok := bytes.Cut(line, sseFieldSeparator)
+		if !ok {
+			return sseEvent{}, false, fmt.Errorf("invalid SSE format: %s", line)
+		}
+
+		switch string(field) {
+		case "event":
+			event.Type = sseEventType(value)
+			dispatch = true
+		case "data":
+			// per the SSE spec, multiple data lines are joined by a newline
+			// with no trailing newline after the last one
+			if hasData {
+				d.data = append(d.data, '\n')
+			}
+			d.data = append(d.data, value...)
+			hasData = true
+			dispatch = true
+		case "retry":
+			// per the SSE spec, values that aren't all ASCII digits are ignored
+			if ms, ok := parseSSERetry(string(value)); ok {
+				d.retry = ms
+			}
+		default:
+			// Ignore unknown fields
+		}
+	}
+
+	if empty {
+		return sseEvent{}, false, io.EOF
+	}
+	if hasData {
+		event.Data = d.data
+	}
+	return event, dispatch, nil
+}
+
+// readLine returns the next line without its line ending, which per the SSE
+// spec may be "\r\n", "\n" or a lone "\r"; some load balancers rewrite one
+// into another. The line is a view into the reader's buffer, or into d.line
+// for a line longer than the buffer, so it is only valid until the next call.
+// A final line without a line ending is returned with io.EOF.
+func (d *sseDecoder) readLine() ([]byte, error) {
+	d.line = d.line[:0]
+	// scanned is how much of the buffered data holds no line ending
+	scanned := 0
+	for {
+		if scanned == d.reader.Size() {
+			// the line is longer than the buffer, so set aside what we have
+			buf, _ := d.reader.Peek(scanned)
+			d.line = append(d.line, buf...)
+			d.reader.Discard(scanned)
+			scanned = 0
+		}
+
+		buf, err := d.reader.Peek(scanned + 1)
+		if len(buf) <= scanned {
+			// the stream ended, or failed, mid-line
+			d.line = append(d.line, buf...)
+			d.reader.Discard(len(buf))
+			return d.line, err
+		}
+		buf, _ = d.reader.Peek(d.reader.Buffered())
+
+		// the "\n" of a "\r\n" split across reads
+		if d.afterCR {
+			d.afterCR = false
+			if buf[0] == '\n' {
+				d.reader.Discard(1)
+				continue
+			}
+		}
+
+		i := bytes.IndexAny(buf[scanned:], "\r\n")
+		if i < 0 {
+			scanned = len(buf)
+			continue
+		}
+		i += scanned
+
+		line := buf[:i]
+		if len(d.line) > 0 {
+			d.line = append(d.line, line...)
+			line = d.line
+		}
+		if buf[i] == '\r' {
+			if i+1 < len(buf) {
+				if buf[i+1] == '\n' {
+					i++
+				}
+			} else {
+				d.afterCR = true
+			}
+		}
+		d.reader.Discard(i + 1)
+		return line, nil
+	}
+}
+
+// sseEventType returns the event type named by b, without allocating for the
+// API's own event types.
+func sseEventType(b []byte) string {
+	switch string(b) {
+	case string(StreamEventMessageStart):
+		return string(StreamEventMessageStart)
+	case string(StreamEventMessageDelta):
+		return string(StreamEventMessageDelta)
+	case string(StreamEventMessageStop):
+		return string(StreamEventMessageStop)
+	case string(StreamEventContentBlockStart):
+		return string(StreamEventContentBlockStart)
+	case string(StreamEventContentBlockDelta):
+		return string(StreamEventContentBlockDelta)
+	case string(StreamEventContentBlockStop):
+		return string(StreamEventContentBlockStop)
+	case string(StreamEventPing):
+		return string(StreamEventPing)
+	case string(StreamEventError):
+		return string(StreamEventError)
+	}
+	return string(b)
+}
+
+// parseSSERetry parses the value of a retry field, a delay in milliseconds.
+func parseSSERetry(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// sendStream sends a streaming request, returning the response once the
+// server has accepted it.
+func (c *Client) sendStream(req *http.Request) (*http.Response, error) {
+	// backends set their own Accept header before authorizing, which may sign it
+	if c.backend == nil {
+		req.Header.Set("Accept", c.streamAccept)
+	}
+
+	started := c.clock.Now()
+	resp, err := c.openStream(req)
+	c.logRequest(req, resp, nil, err, started)
+	return resp, err
+}
+
+// openStream sends req, returning an APIError for an error status.
+func (c *Client) openStream(req *http.Request) (*http.Response, error) {
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,103 @@
+package anthropic
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeAll returns the events r decodes into and the error that ended them.
+// Each event consumes at least one byte, so there are never more events than
+// bytes.
+func decodeAll(t *testing.T, r io.Reader, size int) ([]sseEvent, error) {
+	d := newSSEDecoder(r)
+	var events []sseEvent
+	for {
+		event, err := d.next()
+		if err != nil {
+			return events, err
+		}
+		// Data is only valid until the next call
+		event.Data = bytes.Clone(event.Data)
+		events = append(events, event)
+		if len(events) > size {
+			t.Fatalf("decoded %d events from %d bytes", len(events), size)
+		}
+	}
+}
+
+func TestSSEDecoderChunking(t *testing.T) {
+	long := strings.Repeat("x", 3*4096+7)
+	input := "event: a\r\ndata: " + long + "\r\n\r\nevent: b\rdata: 1\r\r" + "event: c\ndata: 2\n\n"
+	want, err := decodeAll(t, strings.NewReader(input), len(input))
+	assert.Equal(t, io.EOF, err)
+	if assert.Len(t, want, 3) {
+		assert.Equal(t, long, string(want[0].Data))
+	}
+
+	for name, r := range map[string]io.Reader{
+		"one byte": iotest.OneByteReader(strings.NewReader(input)),
+		"half":     iotest.HalfReader(strings.NewReader(input)),
+		"data+err": iotest.DataErrReader(strings.NewReader(input)),
+	} {
+		got, err := decodeAll(t, r, len(input)+16)
+		assert.Equal(t, io.EOF, err, name)
+		assert.Equal(t, want, got, name)
+	}
+}
+
+func FuzzSSEDecoder(f *testing.F) {
+	f.Add([]byte(testTranscript))
+	f.Add([]byte(strings.ReplaceAll(testTranscript, "\n", "\r\n")))
+	f.Add([]byte(strings.ReplaceAll(testTranscript, "\n", "\r")))
+	f.Add([]byte("\xef\xbb\xbfevent: a\r\ndata: 1\r\ndata: 2\r\n\r\nretry: 10\n\n"))
+	f.Add([]byte("data: no event\n\nevent: e\rdata: x\r\n\r"))
+	f.Add([]byte("garbage"))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		// however the input arrives, the parser ends and agrees with itself
+		whole, wholeErr := decodeAll(t, bytes.NewReader(input), len(input))
+		bytewise, bytewiseErr := decodeAll(t, iotest.OneByteReader(bytes.NewReader(input)), len(input))
+		if (wholeErr == io.EOF) != (bytewiseErr == io.EOF) {
+			t.Fatalf("errors differ: %v and %v", wholeErr, bytewiseErr)
+		}
+		if wholeErr == io.EOF && len(whole) != len(bytewise) {
+			t.Fatalf("%d events read whole, %d byte by byte", len(whole), len(bytewise))
+		}
+
+		// and a stream of the events ends too
+		stream := StreamFromReader(io.NopCloser(bytes.NewReader(input)))
+		for i := 0; ; i++ {
+			if i > len(input) {
+				t.Fatalf("stream did not end after %d events", i)
+			}
+			if _, err := stream.Recv(); err != nil {
+				if !errors.Is(err, io.EOF) && stream.Done() {
+					t.Fatalf("error %v after message_stop", err)
+				}
+				break
+			}
+		}
+	})
+}
+
+func BenchmarkSSEDecoder(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchTranscript)))
+	for i := 0; i < b.N; i++ {
+		d := newSSEDecoder(strings.NewReader(benchTranscript))
+		for {
+			if _, err := d.next(); err != nil {
+				if err != io.EOF {
+					b.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package anthropic
+
+import "time"
+
+// StreamStats measures the latency and throughput of a streamed message. See
+// MessageStream.Stats.
+type StreamStats struct {
+	// TimeToFirstToken is the time from sending the request to the first
+	// content delta, or zero if none has arrived yet.
+	TimeToFirstToken time.Duration
+	// Duration is the time since the request was sent, up to the end of the
+	// stream once it has ended.
+	Duration time.Duration
+	// OutputTokensPerSecond is the output tokens reported in the stream's usage
+	// divided by the time since the first content delta, or zero until it can
+	// be measured.
+	OutputTokensPerSecond float64
+	// Events counts the events received by type.
+	Events map[StreamEvent]int
+}
+
+// streamTracker holds the timestamps and counters behind Stats and the
+// WithProgress function, read from the stream's clock once per event.
+type streamTracker struct {
+	clock      Clock
+	started    time.Time
+	firstToken time.Time
+	ended      time.Time
+	events     map[StreamEvent]int
+	count      int
+	blockIndex int
+}
+
+func newStreamTracker(clock Clock, started time.Time) streamTracker {
+	return streamTracker{clock: clock, started: started, events: make(map[StreamEvent]int)}
+}
+
+// record counts event, noting the block it belongs to and the time of the
+// first content delta, and returns the time it arrived.
+func (t *streamTracker) record(event *MessageStreamEvent) time.Time {
+	now := t.clock.Now()
+	t.events[event.Type]++
+	t.count++
+	switch event.Type {
+	case StreamEventContentBlockStart, StreamEventContentBlockDelta:
+		t.blockIndex = event.Index
+		if event.Type == StreamEventContentBlockDelta && t.firstToken.IsZero() {
+			t.firstToken = now
+		}
+	}
+	return now
+}
+
+// end marks the end of the stream, freezing its duration.
+func (t *streamTracker) end() {
+	if t.ended.IsZero() {
+		t.endAt(t.clock.Now())
+	}
+}
+
+// endAt marks the end of the stream at now, the time of its last event.
+func (t *streamTracker) endAt(now time.Time) {
+	if t.ended.IsZero() {
+		t.ended = now
+	}
+}
+
+// Stats returns the stream's latency and throughput so far. Once the stream
+// has ended, by message_stop or an error from Recv, the numbers no longer
+// change.
+func (s *MessageStream) Stats() StreamStats {
+	st := &s.tracker
+	end := st.ended
+	if end.IsZero() {
+		end = st.clock.Now()
+	}
+
+	stats := StreamStats{
+		Duration: end.Sub(st.started),
+		Events:   make(map[StreamEvent]int, len(st.events)),
+	}
+	for typ, n := range st.events {
+		stats.Events[typ] = n
+	}
+	if !st.firstToken.IsZero() {
+		stats.TimeToFirstToken = st.firstToken.Sub(st.started)
+		if generating := end.Sub(st.firstToken); generating > 0 {
+			stats.OutputTokensPerSecond = float64(s.usage.OutputTokens) / generating.Seconds()
+		}
+	}
+	return stats
+}
@@ -0,0 +1,139 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageStreamStats(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	}, WithClock(clock))
+	advance := func(d time.Duration) {
+		clock.mu.Lock()
+		clock.now = clock.now.Add(d)
+		clock.mu.Unlock()
+	}
+
+	stream, err := client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	// message_start and content_block_start arrive 100ms apart
+	advance(100 * time.Millisecond)
+	assert.True(t, stream.Next())
+	advance(100 * time.Millisecond)
+	assert.True(t, stream.Next())
+	stats := stream.Stats()
+	assert.Zero(t, stats.TimeToFirstToken)
+	assert.Zero(t, stats.OutputTokensPerSecond)
+	assert.Equal(t, 200*time.Millisecond, stats.Duration)
+
+	// then each of the rest 500ms after the last
+	for {
+		advance(500 * time.Millisecond)
+		if !stream.Next() {
+			break
+		}
+	}
+	assert.NoError(t, stream.Err())
+
+	stats = stream.Stats()
+	assert.Equal(t, 700*time.Millisecond, stats.TimeToFirstToken)
+	assert.Equal(t, 2700*time.Millisecond, stats.Duration)
+	// 7 output tokens over the 2s from the first delta to message_stop
+	assert.InDelta(t, 7/2.0, stats.OutputTokensPerSecond, 1e-9)
+	assert.Equal(t, map[StreamEvent]int{
+		StreamEventMessageStart:      1,
+		StreamEventContentBlockStart: 1,
+		StreamEventContentBlockDelta: 2,
+		StreamEventContentBlockStop:  1,
+		StreamEventMessageDelta:      1,
+		StreamEventMessageStop:       1,
+	}, stats.Events)
+
+	// the numbers are final once the stream has ended
+	advance(time.Hour)
+	stats.Events[StreamEventMessageStop]++
+	assert.Equal(t, 2700*time.Millisecond, stream.Stats().Duration)
+	assert.Equal(t, 1, stream.Stats().Events[StreamEventMessageStop])
+}
+
+func TestMessageStreamStatsDelayed(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	events := strings.SplitAfter(testTranscript, "\n\n")
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			if strings.HasPrefix(event, "event: content_block_delta") {
+				time.Sleep(delay)
+			}
+			io.WriteString(w, event)
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	stream, err := client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+
+	stats := stream.Stats()
+	assert.GreaterOrEqual(t, stats.TimeToFirstToken, delay)
+	assert.Less(t, stats.TimeToFirstToken, delay+time.Second)
+	assert.GreaterOrEqual(t, stats.Duration, 2*delay)
+	assert.Less(t, stats.Duration, 2*delay+time.Second)
+	assert.Greater(t, stats.OutputTokensPerSecond, 0.0)
+}
+
+// countingClock counts the calls to Now.
+type countingClock struct {
+	fakeClock
+	nows int
+}
+
+func (c *countingClock) Now() time.Time {
+	c.nows++
+	return c.fakeClock.Now()
+}
+
+func TestMessageStreamStatsOneClockRead(t *testing.T) {
+	clock := &countingClock{fakeClock: fakeClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	}, WithClock(clock))
+
+	var infos []ProgressInfo
+	stream, err := client.StreamMessage(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10},
+		WithProgress(func(info ProgressInfo) { infos = append(infos, info) }))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+	meter := stream.RateMeter()
+
+	// stats, progress and the rate meter share one reading per event
+	clock.nows = 0
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, 7, clock.nows)
+	assert.Len(t, infos, 7)
+	assert.Equal(t, 2, meter.Total())
+	assert.Equal(t, 7, infos[6].Events)
+}
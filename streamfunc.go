@@ -0,0 +1,51 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+)
+
+// StreamResult is the outcome of StreamMessageFunc: the assembled message
+// along with the parts of it most often needed afterwards.
+type StreamResult struct {
+	Message      *Message
+	Usage        Usage
+	StopReason   StopReason
+	StopSequence string
+}
+
+// StreamMessageFunc streams a message, passing each event to fn as it arrives,
+// and returns the assembled message once the stream ends. An error from fn
+// stops the stream and is returned as is; one that ends the stream early is
+// returned as a *PartialMessageError.
+func (c *Client) StreamMessageFunc(ctx context.Context, params MessageCreateParams, fn func(event *MessageStreamEvent) error, opts ...RequestOption) (*StreamResult, error) {
+	stream, err := c.StreamMessage(ctx, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	msg, err := accumulateMessage(stream, fn)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamResult{
+		Message:      msg,
+		Usage:        msg.Usage,
+		StopReason:   msg.StopReason,
+		StopSequence: msg.StopSequence,
+	}, nil
+}
+
+// StreamMessageToWriter streams a message, writing the text of each delta to w
+// as soon as it arrives, e.g. to echo a response to the terminal, and returns
+// the assembled message once the stream ends. Nothing more is written to w
+// after an error, whether from the stream or from w itself, which is returned
+// as is.
+func (c *Client) StreamMessageToWriter(ctx context.Context, params MessageCreateParams, w io.Writer, opts ...RequestOption) (*Message, error) {
+	result, err := c.StreamMessageFunc(ctx, params, TextSink(w).WriteEvent, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Message, nil
+}
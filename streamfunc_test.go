@@ -0,0 +1,117 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMessageFunc(t *testing.T) {
+	transcript := strings.Replace(testTranscript,
+		`"stop_reason":"end_turn","stop_sequence":null`,
+		`"stop_reason":"stop_sequence","stop_sequence":"</answer>"`, 1)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, transcript)
+	})
+
+	var events []StreamEvent
+	result, err := client.StreamMessageFunc(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}, func(event *MessageStreamEvent) error {
+		events = append(events, event.Type)
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []StreamEvent{
+		StreamEventMessageStart,
+		StreamEventContentBlockStart,
+		StreamEventContentBlockDelta,
+		StreamEventContentBlockDelta,
+		StreamEventContentBlockStop,
+		StreamEventMessageDelta,
+		StreamEventMessageStop,
+	}, events)
+
+	assert.Equal(t, "msg_01", result.Message.ID)
+	assert.Equal(t, "Hello, world", result.Message.Text())
+	assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 7}, result.Usage)
+	assert.Equal(t, StopReasonStopSequence, result.StopReason)
+	assert.Equal(t, "</answer>", result.StopSequence)
+	assert.Equal(t, result.Usage, result.Message.Usage)
+	assert.Equal(t, result.StopReason, result.Message.StopReason)
+}
+
+func TestStreamMessageFuncCallbackError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, testTranscript)
+	})
+
+	errStop := errors.New("stop")
+	calls := 0
+	result, err := client.StreamMessageFunc(context.Background(), MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}, func(event *MessageStreamEvent) error {
+		calls++
+		if event.Type == StreamEventContentBlockDelta {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Nil(t, result)
+	assert.Equal(t, 3, calls)
+}
+
+// failingWriter records writes, failing each with err if it is set.
+type failingWriter struct {
+	writes []string
+	err    error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+func TestStreamMessageToWriter(t *testing.T) {
+	transcript := testTranscript
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, transcript)
+	})
+	params := MessageCreateParams{Model: ModelClaude3Haiku, MaxTokens: 10}
+
+	var w failingWriter
+	msg, err := client.StreamMessageToWriter(context.Background(), params, &w)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Hello, world", msg.Text())
+		assert.Equal(t, StopReasonEndTurn, msg.StopReason)
+	}
+	assert.Equal(t, []string{"Hello", ", world"}, w.writes, "each delta is written as it arrives")
+
+	errDisk := errors.New("disk full")
+	w = failingWriter{err: errDisk}
+	msg, err = client.StreamMessageToWriter(context.Background(), params, &w)
+	assert.ErrorIs(t, err, errDisk)
+	assert.Nil(t, msg)
+	assert.Equal(t, []string{"Hello"}, w.writes)
+
+	// a stream cut off after the first delta
+	transcript = testTranscript[:strings.Index(testTranscript, "event: content_block_delta")] +
+		"event: content_block_delta\n" +
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello\"}}\n\n"
+	w = failingWriter{}
+	msg, err = client.StreamMessageToWriter(context.Background(), params, &w)
+	var partial *PartialMessageError
+	assert.ErrorAs(t, err, &partial)
+	assert.Nil(t, msg)
+	assert.Equal(t, []string{"Hello"}, w.writes)
+}
@@ -0,0 +1,56 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// charsPerToken is the rough number of characters per token used to judge
+// whether text fits a token budget without counting tokens.
+const charsPerToken = 4
+
+// SummarizeStream reads the text of stream to the end. If it is longer than
+// roughly maxOutputTokens tokens, it asks the model that produced it to
+// summarize the text within that budget and returns the summary instead. A
+// maxOutputTokens of 0 or less means no known limit, and the text is returned
+// as it is.
+func SummarizeStream(ctx context.Context, client *Client, stream *MessageStream, maxOutputTokens int) (string, error) {
+	var text strings.Builder
+	var model string
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case event.Type == StreamEventMessageStart && event.Message != nil:
+			model = event.Message.Model
+		case event.Type == StreamEventContentBlockDelta && event.ContentBlock != nil:
+			text.WriteString(event.ContentBlock.Text)
+		}
+	}
+
+	if maxOutputTokens <= 0 || text.Len() <= maxOutputTokens*charsPerToken {
+		return text.String(), nil
+	}
+
+	summary, err := client.CreateMessage(ctx, MessageCreateParams{
+		Model:     model,
+		MaxTokens: maxOutputTokens,
+		Messages: []MessageParam{{
+			Role: RoleUser,
+			Content: fmt.Sprintf("Summarize the following text in at most %d tokens, keeping its key points. Reply with the summary only.\n\n<text>\n%s\n</text>",
+				maxOutputTokens, text.String()),
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("anthropic: summarizing stream: %w", err)
+	}
+	return summary.Text(), nil
+}
@@ -0,0 +1,76 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func transcriptWithText(text string) string {
+	delta, _ := json.Marshal(text)
+	return "event: message_start\n" +
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\"}}\n\n" +
+		"event: content_block_delta\n" +
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":" + string(delta) + "}}\n\n" +
+		"event: message_stop\n" +
+		"data: {\"type\":\"message_stop\"}\n\n"
+}
+
+func TestSummarizeStreamShort(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("short text must not be summarized")
+	})
+
+	text, err := SummarizeStream(context.Background(), client, newTestStream(t, testTranscript), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, world", text)
+}
+
+func TestSummarizeStreamNoLimit(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("text without a limit must not be summarized")
+	})
+
+	long := strings.Repeat("word ", 100)
+	text, err := SummarizeStream(context.Background(), client, newTestStream(t, transcriptWithText(long)), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, long, text)
+}
+
+func TestSummarizeStreamLong(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+
+	var params MessageCreateParams
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		io.WriteString(w, `{"id":"msg_02","type":"message","role":"assistant","content":[{"type":"text","text":"A lot of words."}]}`)
+	})
+
+	summary, err := SummarizeStream(context.Background(), client, newTestStream(t, transcriptWithText(long)), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "A lot of words.", summary)
+
+	assert.Equal(t, ModelClaude3Haiku, params.Model)
+	assert.Equal(t, 10, params.MaxTokens)
+	if assert.Len(t, params.Messages, 1) {
+		assert.Contains(t, params.Messages[0].Content, fmt.Sprintf("at most %d tokens", 10))
+		assert.Contains(t, params.Messages[0].Content, long)
+	}
+}
+
+func TestSummarizeStreamError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`)
+	})
+
+	_, err := SummarizeStream(context.Background(), client, newTestStream(t, transcriptWithText(strings.Repeat("x", 100))), 1)
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+}
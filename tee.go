@@ -0,0 +1,52 @@
+package anthropic
+
+import (
+	"errors"
+	"io"
+)
+
+// StreamSink receives each event of a stream passed to StreamTee.
+type StreamSink interface {
+	WriteEvent(event *MessageStreamEvent) error
+}
+
+// StreamSinkFunc adapts a function to a StreamSink.
+type StreamSinkFunc func(event *MessageStreamEvent) error
+
+func (f StreamSinkFunc) WriteEvent(event *MessageStreamEvent) error {
+	return f(event)
+}
+
+// TextSink returns a sink that writes the text of each content block delta to
+// w, e.g. to echo a response to the terminal or save it to a file.
+func TextSink(w io.Writer) StreamSink {
+	return StreamSinkFunc(func(event *MessageStreamEvent) error {
+		if event.Type != StreamEventContentBlockDelta || event.ContentBlock == nil || event.ContentBlock.Text == "" {
+			return nil
+		}
+		_, err := io.WriteString(w, event.ContentBlock.Text)
+		return err
+	})
+}
+
+// StreamTee makes stream pass each event to sinks, in order, before Recv
+// returns it. Sink errors don't interrupt the stream; they are collected and
+// reported by Err once it ends. StreamTee returns stream itself.
+func StreamTee(stream *MessageStream, sinks ...StreamSink) *MessageStream {
+	stream.sinks = append(stream.sinks, sinks...)
+	return stream
+}
+
+// dispatch passes event to the stream's sinks, collecting their errors.
+func (s *MessageStream) dispatch(event *MessageStreamEvent) {
+	for _, sink := range s.sinks {
+		if err := sink.WriteEvent(event); err != nil {
+			s.sinkErrs = append(s.sinkErrs, err)
+		}
+	}
+}
+
+// sinkErr returns the errors returned by sinks, joined.
+func (s *MessageStream) sinkErr() error {
+	return errors.Join(s.sinkErrs...)
+}
@@ -0,0 +1,58 @@
+package anthropic
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTee(t *testing.T) {
+	var terminal, log strings.Builder
+	var events []StreamEvent
+	failing := StreamSinkFunc(func(event *MessageStreamEvent) error {
+		if event.Type == StreamEventContentBlockDelta {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	recorder := StreamSinkFunc(func(event *MessageStreamEvent) error {
+		events = append(events, event.Type)
+		return nil
+	})
+	stream := StreamTee(newTestStream(t, testTranscript), TextSink(&terminal), failing, TextSink(&log), recorder)
+
+	// peeked events reach the sinks once, when received
+	_, err := stream.Peek()
+	assert.NoError(t, err)
+
+	var text string
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		if event.Type == StreamEventContentBlockDelta {
+			text += event.ContentBlock.Text
+		}
+	}
+
+	assert.Equal(t, "Hello, world", text)
+	assert.Equal(t, text, terminal.String())
+	assert.Equal(t, text, log.String())
+	assert.Equal(t, []StreamEvent{
+		StreamEventMessageStart,
+		StreamEventContentBlockStart,
+		StreamEventContentBlockDelta,
+		StreamEventContentBlockDelta,
+		StreamEventContentBlockStop,
+		StreamEventMessageDelta,
+		StreamEventMessageStop,
+	}, events)
+	assert.EqualError(t, stream.Err(), "disk full\ndisk full")
+}
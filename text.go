@@ -0,0 +1,238 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrNoJSON = errors.New("anthropic: no JSON object or array found in text")
+
+var jsonFencePattern = regexp.MustCompile("(?s)```(?:json|JSON)?[ \t]*\r?\n(.*?)```")
+
+// ExtractJSON returns the first balanced JSON object or array in text, looking
+// inside ``` fenced blocks first and ignoring any surrounding prose.
+func ExtractJSON(text string) (json.RawMessage, error) {
+	for _, match := range jsonFencePattern.FindAllStringSubmatch(text, -1) {
+		if raw, ok := firstJSONValue(match[1]); ok {
+			return raw, nil
+		}
+	}
+	if raw, ok := firstJSONValue(text); ok {
+		return raw, nil
+	}
+	return nil, ErrNoJSON
+}
+
+// firstJSONValue finds the first '{' or '[' that opens a valid JSON value.
+func firstJSONValue(text string) (json.RawMessage, bool) {
+	for start := 0; start < len(text); start++ {
+		if text[start] != '{' && text[start] != '[' {
+			continue
+		}
+		end := matchingBracket(text, start)
+		if end < 0 {
+			continue
+		}
+		if candidate := text[start : end+1]; json.Valid([]byte(candidate)) {
+			return json.RawMessage(candidate), true
+		}
+	}
+	return nil, false
+}
+
+// matchingBracket returns the index closing the bracket at start, skipping
+// over string literals, or -1 if it is never closed.
+func matchingBracket(text string, start int) int {
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+const (
+	thinkingOpen  = "<thinking>"
+	thinkingClose = "</thinking>"
+)
+
+// ExtractThinking splits text around its first <thinking> block, as produced
+// by prompts asking the model to reason before answering. thinking is the
+// content of the block and response is the text before and after it, both
+// with surrounding whitespace trimmed. A block left unclosed, as when the
+// output hits max tokens, runs to the end of text. If text has no thinking
+// block, thinking is empty and response is text.
+func ExtractThinking(text string) (thinking, response string) {
+	start := strings.Index(text, thinkingOpen)
+	if start < 0 {
+		return "", text
+	}
+	rest := text[start+len(thinkingOpen):]
+	end := strings.Index(rest, thinkingClose)
+	if end < 0 {
+		return strings.TrimSpace(rest), strings.TrimSpace(text[:start])
+	}
+	response = text[:start] + rest[end+len(thinkingClose):]
+	return strings.TrimSpace(rest[:end]), strings.TrimSpace(response)
+}
+
+// StripThinking returns text with every <thinking> block removed and
+// surrounding whitespace trimmed.
+func StripThinking(text string) string {
+	for strings.Contains(text, thinkingOpen) {
+		_, text = ExtractThinking(text)
+	}
+	return strings.TrimSpace(text)
+}
+
+// Text returns the text of the message's text blocks, in order, leaving out
+// thinking and tool use.
+func (m *Message) Text() string {
+	var text strings.Builder
+	for _, block := range m.Content {
+		if block.Type == ContentBlockTypeText {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String()
+}
+
+// DiscardText stops the stream keeping the answer text it reads, for callers
+// that consume the deltas themselves and never call Text. Call it before
+// reading; Text is then empty. OnTextOnly undoes it.
+func (s *MessageStream) DiscardText() {
+	s.answer.discard = true
+}
+
+// Text returns the answer text received so far: the text_delta content of the
+// stream's text blocks in block-index order, leaving out thinking, signatures
+// and tool input. It is empty if DiscardText was called.
+func (s *MessageStream) Text() string {
+	return s.answer.text()
+}
+
+// OnTextOnly calls fn, as events are read, with the answer text as Text
+// reports it. Text is passed on in block-index order: if text blocks are
+// interleaved, the text of a later block is held back until the earlier ones
+// stop.
+func (s *MessageStream) OnTextOnly(fn func(text string)) {
+	s.answer.discard = false
+	s.answer.fn = fn
+}
+
+// answerText accumulates the text blocks of a stream by index.
+type answerText struct {
+	discard bool
+	blocks  map[int]*answerBlock
+	indexes []int
+	fn      func(string)
+}
+
+type answerBlock struct {
+	isText  bool
+	stopped bool
+	text    strings.Builder
+	emitted int
+}
+
+func (a *answerText) block(index int) *answerBlock {
+	if b, ok := a.blocks[index]; ok {
+		return b
+	}
+	if a.blocks == nil {
+		a.blocks = make(map[int]*answerBlock)
+	}
+	b := &answerBlock{}
+	a.blocks[index] = b
+	i := sort.SearchInts(a.indexes, index)
+	a.indexes = append(a.indexes, 0)
+	copy(a.indexes[i+1:], a.indexes[i:])
+	a.indexes[i] = index
+	return b
+}
+
+// observe records event and passes on any text that is now in order.
+func (a *answerText) observe(event *MessageStreamEvent) {
+	switch event.Type {
+	case StreamEventContentBlockStart:
+		if event.ContentBlock != nil && event.ContentBlock.Type == ContentBlockTypeText {
+			b := a.block(event.Index)
+			b.isText = true
+			b.text.WriteString(event.ContentBlock.Text)
+		} else {
+			a.block(event.Index)
+		}
+	case StreamEventContentBlockDelta:
+		if event.ContentBlock == nil || event.ContentBlock.Type != "text_delta" {
+			return
+		}
+		b, started := a.blocks[event.Index]
+		if !started {
+			// no content_block_start was seen, so trust the delta's type
+			b = a.block(event.Index)
+			b.isText = true
+		}
+		if b.isText {
+			b.text.WriteString(event.ContentBlock.Text)
+		}
+	case StreamEventContentBlockStop:
+		a.block(event.Index).stopped = true
+	default:
+		return
+	}
+	a.flush(false)
+}
+
+// flush passes text not yet seen by fn to it, stopping at the first text
+// block still open unless all is set.
+func (a *answerText) flush(all bool) {
+	if a.fn == nil {
+		return
+	}
+	for _, index := range a.indexes {
+		b := a.blocks[index]
+		if !b.isText {
+			continue
+		}
+		if text := b.text.String(); len(text) > b.emitted {
+			a.fn(text[b.emitted:])
+			b.emitted = len(text)
+		}
+		if !b.stopped && !all {
+			return
+		}
+	}
+}
+
+func (a *answerText) text() string {
+	var text strings.Builder
+	for _, index := range a.indexes {
+		if b := a.blocks[index]; b.isText {
+			text.WriteString(b.text.String())
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,205 @@
+package anthropic
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractJSON(t *testing.T) {
+	cases := map[string]struct {
+		text string
+		want string
+	}{
+		"bare object": {
+			text: `{"name": "Ada", "tags": ["a", "b"]}`,
+			want: `{"name": "Ada", "tags": ["a", "b"]}`,
+		},
+		"bare array": {
+			text: `[1, 2, {"three": 3}]`,
+			want: `[1, 2, {"three": 3}]`,
+		},
+		"fenced": {
+			text: "Here you go:\n```json\n{\"ok\": true}\n```\nLet me know if you need more.",
+			want: `{"ok": true}`,
+		},
+		"fence without language": {
+			text: "```\n[\"x\"]\n```",
+			want: `["x"]`,
+		},
+		"prose wrapped": {
+			text: `Sure! The result is {"answer": "use {braces} and \"quotes\""} which should help.`,
+			want: `{"answer": "use {braces} and \"quotes\""}`,
+		},
+		"skips invalid candidates": {
+			text: `Pick one of {a, b} - final: {"choice": "b"}`,
+			want: `{"choice": "b"}`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			raw, err := ExtractJSON(tc.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, string(raw))
+		})
+	}
+
+	_, err := ExtractJSON("I'm sorry, I can't produce that as JSON.")
+	assert.ErrorIs(t, err, ErrNoJSON)
+	_, err = ExtractJSON(`{"unterminated": true`)
+	assert.ErrorIs(t, err, ErrNoJSON)
+}
+
+func TestExtractThinking(t *testing.T) {
+	cases := map[string]struct {
+		text     string
+		thinking string
+		response string
+	}{
+		"leading block": {
+			text:     "<thinking>\nThe user wants a greeting.\n</thinking>\n\nHello!",
+			thinking: "The user wants a greeting.",
+			response: "Hello!",
+		},
+		"text around block": {
+			text:     "Let me check. <thinking>2 + 2 = 4</thinking> The answer is 4.",
+			thinking: "2 + 2 = 4",
+			response: "Let me check.  The answer is 4.",
+		},
+		"only first block": {
+			text:     "<thinking>one</thinking>A<thinking>two</thinking>B",
+			thinking: "one",
+			response: "A<thinking>two</thinking>B",
+		},
+		"unclosed": {
+			text:     "Answer pending <thinking>still working it out",
+			thinking: "still working it out",
+			response: "Answer pending",
+		},
+		"no block": {
+			text:     "Just an answer.",
+			response: "Just an answer.",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			thinking, response := ExtractThinking(tc.text)
+			assert.Equal(t, tc.thinking, thinking)
+			assert.Equal(t, tc.response, response)
+		})
+	}
+}
+
+func TestStripThinking(t *testing.T) {
+	assert.Equal(t, "A\nB", StripThinking("<thinking>one</thinking>A\n<thinking>two</thinking>B"))
+	assert.Equal(t, "Hello", StripThinking("Hello<thinking>truncated"))
+	assert.Equal(t, "Hello", StripThinking("Hello"))
+}
+
+// thinkingToolTranscript streams a thinking block, a text block and a tool
+// call, as a model with extended thinking and tools does.
+const thinkingToolTranscript = "event: message_start\n" +
+	"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[]}}\n\n" +
+	"event: content_block_start\n" +
+	"data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"The user wants the weather.\"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"signature_delta\",\"signature\":\"EqQBCgIYAhIM\"}}\n\n" +
+	"event: content_block_stop\n" +
+	"data: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+	"event: content_block_start\n" +
+	"data: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"Let me check \"}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"the weather.\"}}\n\n" +
+	"event: content_block_stop\n" +
+	"data: {\"type\":\"content_block_stop\",\"index\":1}\n\n" +
+	"event: content_block_start\n" +
+	"data: {\"type\":\"content_block_start\",\"index\":2,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_01\",\"name\":\"get_weather\",\"input\":{}}}\n\n" +
+	"event: content_block_delta\n" +
+	"data: {\"type\":\"content_block_delta\",\"index\":2,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\": \\\"Paris\\\"}\"}}\n\n" +
+	"event: content_block_stop\n" +
+	"data: {\"type\":\"content_block_stop\",\"index\":2}\n\n" +
+	"event: message_delta\n" +
+	"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":40}}\n\n" +
+	"event: message_stop\n" +
+	"data: {\"type\":\"message_stop\"}\n\n"
+
+func TestMessageStreamTextOnly(t *testing.T) {
+	stream := newTestStream(t, thinkingToolTranscript)
+	var chunks []string
+	stream.OnTextOnly(func(text string) { chunks = append(chunks, text) })
+
+	var deltas []string
+	for stream.Next() {
+		if event := stream.Event(); event.Type == StreamEventContentBlockDelta {
+			deltas = append(deltas, string(event.ContentBlock.Type))
+		}
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, []string{"thinking_delta", "signature_delta", "text_delta", "text_delta", "input_json_delta"}, deltas)
+	assert.Equal(t, "Let me check the weather.", stream.Text())
+	assert.Equal(t, []string{"Let me check ", "the weather."}, chunks)
+}
+
+func TestMessageStreamDiscardText(t *testing.T) {
+	// text is kept by default
+	stream := newTestStream(t, thinkingToolTranscript)
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, "Let me check the weather.", stream.Text())
+
+	// with DiscardText the stream keeps none of it
+	stream = newTestStream(t, thinkingToolTranscript)
+	stream.DiscardText()
+	for stream.Next() {
+	}
+	assert.NoError(t, stream.Err())
+	assert.Empty(t, stream.Text())
+	assert.Nil(t, stream.answer.blocks)
+}
+
+func TestMessageStreamTextOnlyInterleaved(t *testing.T) {
+	delta := func(index int, deltaType, field, value string) string {
+		return fmt.Sprintf("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":%d,\"delta\":{\"type\":%q,%q:%q}}\n\n", index, deltaType, field, value)
+	}
+	start := func(index int, blockType string) string {
+		return fmt.Sprintf("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":%d,\"content_block\":{\"type\":%q}}\n\n", index, blockType)
+	}
+	stop := func(index int) string {
+		return fmt.Sprintf("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":%d}\n\n", index)
+	}
+	stream := newTestStream(t, start(0, "text")+start(1, "thinking")+start(2, "text")+
+		delta(2, "text_delta", "text", "world")+
+		delta(0, "text_delta", "text", "Hello")+
+		delta(1, "thinking_delta", "thinking", "hmm")+
+		delta(0, "text_delta", "text", ", ")+
+		stop(0)+
+		delta(2, "text_delta", "text", "!")+
+		stop(1)+stop(2)+
+		// a block cut off by the end of the stream is still passed on
+		start(3, "text")+delta(3, "text_delta", "text", " Bye"))
+
+	var chunks []string
+	stream.OnTextOnly(func(text string) { chunks = append(chunks, text) })
+	for stream.Next() {
+		// block 2's text is held back until block 0 stops
+	}
+	assert.NoError(t, stream.Err())
+	assert.Equal(t, "Hello, world! Bye", stream.Text())
+	assert.Equal(t, []string{"Hello", ", ", "world", "!", " Bye"}, chunks)
+}
+
+func TestMessageText(t *testing.T) {
+	msg := &Message{Content: []ContentBlock{
+		{Type: ContentBlockTypeThinking, Thinking: "The user wants the weather.", Signature: "EqQBCgIYAhIM"},
+		{Type: ContentBlockTypeText, Text: "Let me check "},
+		{Type: ContentBlockTypeToolUse, ID: "toolu_01", Name: "get_weather", Input: []byte(`{"city":"Paris"}`)},
+		{Type: ContentBlockTypeText, Text: "the weather."},
+	}}
+	assert.Equal(t, "Let me check the weather.", msg.Text())
+}
@@ -0,0 +1,70 @@
+package anthropic
+
+import "fmt"
+
+// Thinking enables extended thinking, letting the model reason for up to
+// BudgetTokens before answering. The budget counts towards max_tokens, so it
+// must be less than it.
+type Thinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+// ThinkingEnabled returns a Thinking allowing the model budget tokens of
+// reasoning.
+func ThinkingEnabled(budget int) *Thinking {
+	return &Thinking{Type: "enabled", BudgetTokens: budget}
+}
+
+// WithThinkingBudget enables extended thinking with budget tokens for message
+// requests that don't set Thinking. Requests whose max_tokens doesn't exceed
+// the budget fail validation unless WithThinkingAnswerTokens is also given.
+func WithThinkingBudget(budget int) ClientOption {
+	return func(c *Client) {
+		c.thinkingBudget = budget
+	}
+}
+
+// WithThinkingAnswerTokens makes message requests with thinking enabled raise
+// max_tokens, when it is too small, to the thinking budget plus tokens, so
+// that room is left for the answer rather than the request being rejected.
+func WithThinkingAnswerTokens(tokens int) ClientOption {
+	return func(c *Client) {
+		c.thinkingAnswerTokens = tokens
+	}
+}
+
+// applyThinkingDefaults enables thinking on params as configured by
+// WithThinkingBudget and makes room for the answer as configured by
+// WithThinkingAnswerTokens.
+func (c *Client) applyThinkingDefaults(params MessageCreateParams) MessageCreateParams {
+	if params.Thinking == nil && c.thinkingBudget > 0 {
+		params.Thinking = ThinkingEnabled(c.thinkingBudget)
+	}
+	if params.Thinking == nil || params.Thinking.Type != "enabled" || c.thinkingAnswerTokens <= 0 {
+		return params
+	}
+	if minimum := params.Thinking.BudgetTokens + c.thinkingAnswerTokens; params.MaxTokens < minimum {
+		params.MaxTokens = minimum
+	}
+	return params
+}
+
+func validateThinking(thinking *Thinking, maxTokens int) error {
+	if thinking == nil {
+		return nil
+	}
+	switch thinking.Type {
+	case "enabled":
+		if thinking.BudgetTokens <= 0 {
+			return fmt.Errorf("anthropic: thinking budget_tokens must be positive")
+		}
+		if thinking.BudgetTokens >= maxTokens {
+			return fmt.Errorf("anthropic: thinking budget_tokens (%d) must be less than max_tokens (%d), leaving room for the answer", thinking.BudgetTokens, maxTokens)
+		}
+	case "disabled":
+	default:
+		return fmt.Errorf("anthropic: unknown thinking type %q", thinking.Type)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateThinking(t *testing.T) {
+	params := MessageCreateParams{
+		Model:     ModelClaude35Sonnet,
+		MaxTokens: 2048,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Hi"}},
+		Thinking:  ThinkingEnabled(2048),
+	}
+	assert.EqualError(t, params.Validate(), "anthropic: thinking budget_tokens (2048) must be less than max_tokens (2048), leaving room for the answer")
+
+	params.Thinking = ThinkingEnabled(0)
+	assert.EqualError(t, params.Validate(), "anthropic: thinking budget_tokens must be positive")
+	params.Thinking = &Thinking{Type: "on"}
+	assert.EqualError(t, params.Validate(), `anthropic: unknown thinking type "on"`)
+
+	params.Thinking = ThinkingEnabled(1024)
+	assert.NoError(t, params.Validate())
+	params.Thinking = &Thinking{Type: "disabled"}
+	assert.NoError(t, params.Validate())
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must be rejected locally")
+	}, WithThinkingBudget(4096))
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 1024})
+	assert.EqualError(t, err, "anthropic: thinking budget_tokens (4096) must be less than max_tokens (1024), leaving room for the answer")
+}
+
+func TestWithThinkingAnswerTokens(t *testing.T) {
+	var sent []MessageCreateParams
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params MessageCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		sent = append(sent, params)
+		w.Write([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Ok"}]}`))
+	}, WithThinkingBudget(4096), WithThinkingAnswerTokens(1000))
+
+	// max_tokens is raised to fit the budget and the answer
+	_, err := client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 1024})
+	assert.NoError(t, err)
+	// a large enough max_tokens is left alone, as is an explicit budget
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 8192})
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 1024, Thinking: ThinkingEnabled(2000)})
+	assert.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 1024, Thinking: &Thinking{Type: "disabled"}})
+	assert.NoError(t, err)
+
+	if !assert.Len(t, sent, 4) {
+		return
+	}
+	assert.Equal(t, ThinkingEnabled(4096), sent[0].Thinking)
+	assert.Equal(t, 5096, sent[0].MaxTokens)
+	assert.Equal(t, 8192, sent[1].MaxTokens)
+	assert.Equal(t, ThinkingEnabled(2000), sent[2].Thinking)
+	assert.Equal(t, 3000, sent[2].MaxTokens)
+	assert.Equal(t, &Thinking{Type: "disabled"}, sent[3].Thinking)
+	assert.Equal(t, 1024, sent[3].MaxTokens)
+}
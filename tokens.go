@@ -0,0 +1,50 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+)
+
+// TokenCount is the response to CountTokens.
+type TokenCount struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+// countTokensParams is the subset of MessageCreateParams the count_tokens
+// endpoint accepts.
+type countTokensParams struct {
+	Messages   []MessageParam `json:"messages"`
+	Model      string         `json:"model"`
+	System     string         `json:"system,omitempty"`
+	Thinking   *Thinking      `json:"thinking,omitempty"`
+	ToolChoice *ToolChoice    `json:"tool_choice,omitempty"`
+	Tools      []Tool         `json:"tools,omitempty"`
+	MCPServers []MCPServer    `json:"mcp_servers,omitempty"`
+}
+
+// CountTokens returns how many input tokens params would use, without
+// creating a message. Fields that only affect generation, such as MaxTokens
+// and Temperature, are not sent.
+func (c *Client) CountTokens(ctx context.Context, params MessageCreateParams, opts ...RequestOption) (*TokenCount, error) {
+	params = c.applyDefaults(ctx, params)
+	body := countTokensParams{
+		Messages:   params.Messages,
+		Model:      params.Model,
+		System:     params.System,
+		Thinking:   params.Thinking,
+		ToolChoice: params.ToolChoice,
+		Tools:      params.Tools,
+		MCPServers: params.MCPServers,
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/messages/count_tokens", nil, body, prependOptions(withModel(params.Model), c.betaOptions(params, opts))...)
+	if err != nil {
+		return nil, err
+	}
+
+	var count TokenCount
+	if _, err := c.do(req, &count); err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
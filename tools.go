@@ -0,0 +1,84 @@
+package anthropic
+
+import "fmt"
+
+type Tool struct {
+	// Type is empty for custom tools and names the version of a tool built
+	// into the API, such as "computer_20241022".
+	Type         string        `json:"type,omitempty"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	InputSchema  interface{}   `json:"input_schema,omitempty"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+
+	// Set on computer use tools.
+	DisplayWidthPx  int `json:"display_width_px,omitempty"`
+	DisplayHeightPx int `json:"display_height_px,omitempty"`
+	DisplayNumber   int `json:"display_number,omitempty"`
+}
+
+// MCPServer is a remote MCP server whose tools the model may call.
+type MCPServer struct {
+	Type               string `json:"type"`
+	URL                string `json:"url"`
+	Name               string `json:"name"`
+	AuthorizationToken string `json:"authorization_token,omitempty"`
+}
+
+type ToolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+// ToolChoiceAuto lets the model decide whether to call a tool.
+func ToolChoiceAuto() *ToolChoice {
+	return &ToolChoice{Type: "auto"}
+}
+
+// ToolChoiceAny requires the model to call one of the provided tools.
+func ToolChoiceAny() *ToolChoice {
+	return &ToolChoice{Type: "any"}
+}
+
+// ToolChoiceTool requires the model to call the named tool.
+func ToolChoiceTool(name string) *ToolChoice {
+	return &ToolChoice{Type: "tool", Name: name}
+}
+
+// ToolChoiceNone prevents the model from calling tools while still letting it
+// see their definitions.
+func ToolChoiceNone() *ToolChoice {
+	return &ToolChoice{Type: "none"}
+}
+
+func validateTools(tools []Tool, choice *ToolChoice) error {
+	names := make(map[string]bool, len(tools))
+	for i, tool := range tools {
+		if tool.Name == "" {
+			return fmt.Errorf("anthropic: tool %d has no name", i)
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("anthropic: duplicate tool name %q", tool.Name)
+		}
+		names[tool.Name] = true
+	}
+
+	if choice == nil {
+		return nil
+	}
+	switch choice.Type {
+	case "auto", "none":
+	case "any":
+		if len(tools) == 0 {
+			return fmt.Errorf("anthropic: tool_choice %q requires tools", choice.Type)
+		}
+	case "tool":
+		if !names[choice.Name] {
+			return fmt.Errorf("anthropic: tool_choice names unknown tool %q", choice.Name)
+		}
+	default:
+		return fmt.Errorf("anthropic: unknown tool_choice type %q", choice.Type)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var weatherTool = Tool{
+	Name:        "get_weather",
+	Description: "Get the current weather in a given location",
+	InputSchema: map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"location": map[string]interface{}{"type": "string"}},
+		"required":   []string{"location"},
+	},
+}
+
+func TestToolChoiceNone(t *testing.T) {
+	params := MessageCreateParams{
+		Model:      ModelClaude35Sonnet,
+		MaxTokens:  100,
+		Messages:   []MessageParam{{Role: RoleUser, Content: "What tools could you use?"}},
+		Tools:      []Tool{weatherTool},
+		ToolChoice: ToolChoiceNone(),
+	}
+	assert.NoError(t, params.Validate())
+
+	b, err := json.Marshal(params)
+	assert.NoError(t, err)
+	var body map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(b, &body))
+	assert.JSONEq(t, `{"type":"none"}`, string(body["tool_choice"]))
+
+	params.Tools = nil
+	assert.NoError(t, params.Validate())
+}
+
+func TestToolChoiceValidation(t *testing.T) {
+	base := MessageCreateParams{Model: ModelClaude35Sonnet, MaxTokens: 100, Tools: []Tool{weatherTool}}
+
+	for _, choice := range []*ToolChoice{nil, ToolChoiceAuto(), ToolChoiceAny(), ToolChoiceTool("get_weather")} {
+		params := base
+		params.ToolChoice = choice
+		assert.NoError(t, params.Validate())
+	}
+
+	params := base
+	params.ToolChoice = ToolChoiceTool("get_time")
+	assert.Error(t, params.Validate())
+
+	params = base
+	params.ToolChoice = &ToolChoice{Type: "sometimes"}
+	assert.Error(t, params.Validate())
+
+	params = base
+	params.Tools = nil
+	params.ToolChoice = ToolChoiceAny()
+	assert.Error(t, params.Validate())
+
+	params = base
+	params.Tools = []Tool{weatherTool, weatherTool}
+	assert.Error(t, params.Validate())
+
+	params = base
+	params.Model = ModelClaude21
+	assert.EqualError(t, params.Validate(), "anthropic: model claude-2.1 does not support tools")
+}
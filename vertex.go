@@ -0,0 +1,103 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// vertexAPIVersion is sent in the body of Vertex AI requests in place of the
+// anthropic-version header.
+const vertexAPIVersion = "vertex-2023-10-16"
+
+// TokenSource supplies the OAuth2 access token sent to Vertex AI with every
+// request. It keeps golang.org/x/oauth2 out of this module's dependencies; an
+// oauth2.TokenSource is adapted with TokenSourceFunc:
+//
+//	anthropic.TokenSourceFunc(func(context.Context) (string, error) {
+//		tok, err := ts.Token()
+//		if err != nil {
+//			return "", err
+//		}
+//		return tok.AccessToken, nil
+//	})
+type TokenSource interface {
+	AccessToken(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) AccessToken(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type vertexConfig struct {
+	project     string
+	region      string
+	tokenSource TokenSource
+}
+
+// WithVertex routes message requests through Google Vertex AI in project and
+// region, authenticating with tokens from ts. The model moves from the request
+// body into the URL and the API version into the body; CreateMessage and
+// StreamMessage are otherwise unchanged. Other endpoints are not available on
+// Vertex AI and fail. A base URL set with WithBaseURL replaces the regional
+// Vertex AI endpoint.
+func WithVertex(project, region string, ts TokenSource) ClientOption {
+	return func(c *Client) {
+		c.backend = &vertexConfig{project: project, region: region, tokenSource: ts}
+	}
+}
+
+// baseURL returns the Vertex AI endpoint for the configured region.
+func (v *vertexConfig) baseURL() string {
+	if v.region == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", v.region)
+}
+
+// rewrite returns the Vertex AI URL and body for a request to path with the
+// serialized body.
+func (v *vertexConfig) rewrite(baseURL, path string, body []byte) (string, []byte, bool, error) {
+	if path != "/v1/messages" {
+		return "", nil, false, fmt.Errorf("anthropic: %s is not supported on Vertex AI", path)
+	}
+
+	b, err := parseBackendBody("Vertex AI", body)
+	if err != nil {
+		return "", nil, false, err
+	}
+	body, err = b.marshal(vertexAPIVersion)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	method := "rawPredict"
+	if b.stream {
+		method = "streamRawPredict"
+	}
+	reqURL, err := url.JoinPath(baseURL, "v1", "projects", v.project, "locations", v.region,
+		"publishers", "anthropic", "models", b.model+":"+method)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("anthropic: invalid base URL %q: %w", baseURL, err)
+	}
+	return reqURL, body, b.stream, nil
+}
+
+func (v *vertexConfig) authorize(req *http.Request, _ []byte) error {
+	token, err := v.tokenSource.AccessToken(req.Context())
+	if err != nil {
+		return fmt.Errorf("anthropic: getting Vertex AI access token: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return nil
+}
+
+// newDecoder returns an SSE decoder; Vertex AI streams the API's own events.
+func (v *vertexConfig) newDecoder(r io.Reader) eventDecoder {
+	return newSSEDecoder(r)
+}
@@ -0,0 +1,87 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// vertexTokenSource stands in for Google's default credentials.
+var vertexTokenSource = TokenSourceFunc(func(context.Context) (string, error) {
+	return "ya29.token", nil
+})
+
+func TestVertexCreateMessage(t *testing.T) {
+	client, requests := newRecordingTestClient(t, respondWith([]byte(`{"id":"msg_01","type":"message","role":"assistant","content":[{"type":"text","text":"Ok"}],"model":"claude-3-haiku@20240307","stop_reason":"end_turn","usage":{"input_tokens":8,"output_tokens":1}}`)), WithVertex("my-project", "us-east5", vertexTokenSource))
+
+	msg, err := client.CreateMessage(context.Background(), MessageCreateParams{
+		Model:     "claude-3-haiku@20240307",
+		MaxTokens: 10,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Ok", msg.Content[0].Text)
+
+	if !assert.Len(t, *requests, 1) {
+		return
+	}
+	req := (*requests)[0]
+	assert.Equal(t, "/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/claude-3-haiku@20240307:rawPredict", req.path)
+	assert.Equal(t, "Bearer ya29.token", req.header.Get("Authorization"))
+	assert.Empty(t, req.header.Get("X-API-Key"))
+	assert.Empty(t, req.header.Get("anthropic-version"))
+	assert.NotContains(t, req.body, "model")
+	assert.Equal(t, "vertex-2023-10-16", req.body["anthropic_version"])
+	assert.Equal(t, float64(10), req.body["max_tokens"])
+}
+
+func TestVertexStreamMessage(t *testing.T) {
+	client, requests := newRecordingTestClient(t, respondWith([]byte(testTranscript)), WithVertex("my-project", "us-east5", vertexTokenSource))
+
+	stream, err := client.StreamMessage(context.Background(), MessageCreateParams{
+		Model:     "claude-3-haiku@20240307",
+		MaxTokens: 10,
+		Messages:  []MessageParam{{Role: RoleUser, Content: "Hi"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer stream.Close()
+
+	var text string
+	for {
+		m, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		if m.Type == StreamEventContentBlockDelta {
+			text += m.ContentBlock.Text
+		}
+	}
+	assert.Equal(t, "Hello, world", text)
+
+	req := (*requests)[0]
+	assert.Equal(t, "/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/claude-3-haiku@20240307:streamRawPredict", req.path)
+	assert.Equal(t, "Bearer ya29.token", req.header.Get("Authorization"))
+	assert.Equal(t, true, req.body["stream"])
+	assert.NotContains(t, req.body, "model")
+}
+
+func TestVertexUnsupportedEndpoint(t *testing.T) {
+	client, requests := newRecordingTestClient(t, respondWith([]byte(`{}`)), WithVertex("my-project", "us-east5", vertexTokenSource))
+
+	_, err := client.ListModels(context.Background(), ListParams{})
+	assert.EqualError(t, err, "anthropic: /v1/models is not supported on Vertex AI")
+	assert.Empty(t, *requests)
+}
+
+func TestVertexBaseURL(t *testing.T) {
+	assert.Equal(t, "https://us-east5-aiplatform.googleapis.com", (&vertexConfig{region: "us-east5"}).baseURL())
+	assert.Equal(t, "https://aiplatform.googleapis.com", (&vertexConfig{region: "global"}).baseURL())
+}